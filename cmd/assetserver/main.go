@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"io/fs"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/project-midgard/midgarts/resource"
+	"golang.org/x/text/encoding/korean"
+)
+
+const (
+	// minimapPath is "data\texture\유저인터페이스\map\", EUC-KR encoded like GRF entry names.
+	minimapPath = "data\\texture\\\xc0\xaf\xc0\xfa\xc0\xce\xc5\xcd\xc6\xe4\xc0\xcc\xbd\xba\\map\\"
+)
+
+// server serves resources from a stack of GRF files. Files mounted first
+// take precedence over the ones after them.
+type server struct {
+	loader resource.Loader
+}
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	useMmap := flag.Bool("mmap", false, "map GRF files into memory instead of reading them")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: assetserver [-addr :8080] [-mmap] file.grf [file.grf...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		return
+	}
+
+	var files []*grf.File
+	for _, name := range flag.Args() {
+		f, err := grf.NewFileWithOptions(name, grf.OpenOptions{Mmap: *useMmap})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("mounted %s (%d entries)\n", name, len(f.GetEntries()))
+		files = append(files, f)
+	}
+
+	s := &server{loader: resource.NewLoader(resource.NewGRFSource(files...))}
+
+	http.HandleFunc("/grf/", s.handleEntry)
+	http.HandleFunc("/spr/", s.handleSpriteFrame)
+	http.HandleFunc("/map/", s.handleMinimap)
+
+	log.Printf("listening on %s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+// handleEntry serves /grf/data/sprite/foo.spr as the raw "data\sprite\foo.spr" entry.
+func (s *server) handleEntry(w http.ResponseWriter, r *http.Request) {
+	name, err := entryName(strings.TrimPrefix(r.URL.Path, "/grf/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	data, err := s.loader.ReadFile(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(r.URL.Path))
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	_, _ = w.Write(data)
+}
+
+// handleSpriteFrame serves /spr/data/sprite/foo.spr/frame/3.png as a PNG image.
+// An optional ?scale=2 or ?scale=4 enlarges it with a pixel-art scaler.
+func (s *server) handleSpriteFrame(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/spr/"), "/frame/", 2)
+	if len(parts) != 2 || !strings.HasSuffix(parts[1], ".png") {
+		http.NotFound(w, r)
+		return
+	}
+
+	index, err := strconv.Atoi(strings.TrimSuffix(parts[1], ".png"))
+	if err != nil {
+		http.Error(w, "invalid frame index", http.StatusBadRequest)
+		return
+	}
+
+	opts := spr.ConvertOptions{Scale: 1}
+	if value := r.URL.Query().Get("scale"); value != "" {
+		if opts.Scale, err = strconv.Atoi(value); err != nil || (opts.Scale != 2 && opts.Scale != 4) {
+			http.Error(w, "scale must be 2 or 4", http.StatusBadRequest)
+			return
+		}
+	}
+
+	name, err := entryName(parts[0])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	sprite, err := s.loader.LoadSprite(name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	img, err := sprite.ConvertFrame(index, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	writePNG(w, img)
+}
+
+// handleMinimap serves /map/prontera/minimap.png from the map's minimap bitmap.
+func (s *server) handleMinimap(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/map/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "minimap.png" {
+		http.NotFound(w, r)
+		return
+	}
+
+	name, err := entryName(parts[0])
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	img, err := s.loader.LoadTexture(minimapPath + name + ".bmp")
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writePNG(w, img)
+}
+
+// writeError responds with 404 for missing resources and 422 for the ones
+// that could not be decoded.
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+}
+
+// entryName converts a UTF-8 URL path into an EUC-KR, backslash separated
+// entry name.
+func entryName(urlPath string) (string, error) {
+	name, err := korean.EUCKR.NewEncoder().String(urlPath)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ReplaceAll(name, "/", "\\"), nil
+}
+
+func writePNG(w http.ResponseWriter, img image.Image) {
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(buf.Bytes())
+}
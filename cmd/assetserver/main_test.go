@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/project-midgard/midgarts/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+// mapSource serves entries by their exact GRF name, like GRFSource does.
+type mapSource map[string][]byte
+
+func (s mapSource) ReadFile(name string) ([]byte, error) {
+	data, ok := s[name]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+
+	return data, nil
+}
+
+func TestHandlers(t *testing.T) {
+	const dir = "data\\sprite\\\xc0\xaf\xc0\xfa\\"
+
+	s := &server{loader: resource.NewLoader(mapSource{
+		dir + "test.txt": []byte("hello"),
+		dir + "test.spr": sprtest.Encode([]sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0x01}}}),
+	})}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/grf/", s.handleEntry)
+	mux.HandleFunc("/spr/", s.handleSpriteFrame)
+
+	var tests = []struct {
+		Name               string
+		Path               string
+		ExpectedStatusCode int
+		ExpectedType       string
+	}{
+		{
+			Name:               "serve entry in korean directory",
+			Path:               "/grf/data/sprite/%EC%9C%A0%EC%A0%80/test.txt",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedType:       "text/plain; charset=utf-8",
+		},
+		{
+			Name:               "serve sprite frame in korean directory",
+			Path:               "/spr/data/sprite/%EC%9C%A0%EC%A0%80/test.spr/frame/0.png",
+			ExpectedStatusCode: http.StatusOK,
+			ExpectedType:       "image/png",
+		},
+		{
+			Name:               "missing entry",
+			Path:               "/grf/data/sprite/%EC%9C%A0%EC%A0%80/missing.txt",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+		{
+			Name:               "name without an EUC-KR encoding",
+			Path:               "/grf/data/sprite/%F0%9F%98%80.txt",
+			ExpectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.Path, nil))
+
+			assert.Equal(t, tt.ExpectedStatusCode, rec.Code)
+			if tt.ExpectedType != "" {
+				assert.Equal(t, tt.ExpectedType, rec.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+	"text/tabwriter"
+
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/project-midgard/midgarts/fileformat/spr"
+)
+
+// result is the part of a benchmark run that is saved and compared.
+type result struct {
+	NsPerOp     int64   `json:"nsPerOp"`
+	MBPerSec    float64 `json:"mbPerSec"`
+	BytesPerOp  int64   `json:"bytesPerOp"`
+	AllocsPerOp int64   `json:"allocsPerOp"`
+}
+
+type benchmark struct {
+	Name string
+	Func func(b *testing.B)
+}
+
+func main() {
+	var (
+		baselinePath = flag.String("baseline", "", "compare against results saved in this file")
+		savePath     = flag.String("save", "", "save results to this file")
+	)
+
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: bench [-baseline old.json] [-save new.json] file.grf")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	baseline := make(map[string]result)
+	if *baselinePath != "" {
+		data, err := ioutil.ReadFile(*baselinePath)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err = json.Unmarshal(data, &baseline); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	benchmarks, err := grfBenchmarks(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	results := make(map[string]result, len(benchmarks))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "benchmark\tns/op\tMB/s\tB/op\tallocs/op\tdelta\t")
+
+	for _, bb := range benchmarks {
+		r := testing.Benchmark(bb.Func)
+
+		res := result{
+			NsPerOp:     r.NsPerOp(),
+			BytesPerOp:  r.AllocedBytesPerOp(),
+			AllocsPerOp: r.AllocsPerOp(),
+		}
+
+		if r.Bytes > 0 && r.T > 0 {
+			res.MBPerSec = float64(r.Bytes) * float64(r.N) / 1e6 / r.T.Seconds()
+		}
+
+		results[bb.Name] = res
+
+		delta := "-"
+		if old, ok := baseline[bb.Name]; ok && old.NsPerOp > 0 {
+			delta = fmt.Sprintf("%+.1f%%", float64(res.NsPerOp-old.NsPerOp)*100/float64(old.NsPerOp))
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%.2f\t%d\t%d\t%s\t\n", bb.Name, res.NsPerOp, res.MBPerSec, res.BytesPerOp, res.AllocsPerOp, delta)
+	}
+
+	_ = w.Flush()
+
+	if *savePath != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err = ioutil.WriteFile(*savePath, data, 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// grfBenchmarks returns the benchmarks that run against the archive at path.
+// Entries that can't be read are left out, sprite benchmarks use every .spr
+// entry of the archive.
+func grfBenchmarks(grfPath string) ([]benchmark, error) {
+	f, err := grf.NewFile(grfPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		names   []string
+		total   int64
+		sprites [][]byte
+	)
+
+	for name := range f.GetEntries() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	readable := names[:0]
+	for _, name := range names {
+		e, err := f.GetEntry(name)
+		if err != nil {
+			log.Printf("skipping %s: %v\n", name, err)
+			continue
+		}
+
+		readable = append(readable, name)
+		total += int64(e.Header.UncompressedSize)
+
+		if strings.ToLower(path.Ext(name)) == ".spr" {
+			sprites = append(sprites, append([]byte(nil), e.Data.Bytes()...))
+		}
+	}
+	names = readable
+
+	benchmarks := []benchmark{
+		{
+			Name: "grf/open",
+			Func: func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					g, err := grf.NewFile(grfPath)
+					if err != nil {
+						log.Fatal(err)
+					}
+					_ = g.Close()
+				}
+			},
+		},
+		{
+			Name: "grf/read-all",
+			Func: func(b *testing.B) {
+				b.SetBytes(total)
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					for _, name := range names {
+						if _, err := f.GetEntry(name); err != nil {
+							log.Fatal(err)
+						}
+					}
+				}
+			},
+		},
+	}
+
+	return append(benchmarks, spriteBenchmarks(sprites)...), nil
+}
+
+// spriteBenchmarks returns the sprite benchmarks, run against the sprites
+// that load. The others are skipped and counted in the log.
+func spriteBenchmarks(sprites [][]byte) []benchmark {
+	var (
+		files    []*spr.SpriteFile
+		loadable = sprites[:0]
+	)
+
+	for _, data := range sprites {
+		if file, err := spr.Load(bytes.NewReader(data)); err == nil {
+			files = append(files, file)
+			loadable = append(loadable, data)
+		}
+	}
+
+	if skipped := len(sprites) - len(loadable); skipped > 0 {
+		log.Printf("skipping %d sprites that could not be loaded\n", skipped)
+	}
+	sprites = loadable
+
+	if len(sprites) == 0 {
+		return nil
+	}
+
+	var spriteBytes int64
+	for _, data := range sprites {
+		spriteBytes += int64(len(data))
+	}
+
+	return []benchmark{
+		{
+			Name: "spr/load",
+			Func: func(b *testing.B) {
+				b.SetBytes(spriteBytes)
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					for _, data := range sprites {
+						if _, err := spr.Load(bytes.NewReader(data)); err != nil {
+							log.Fatal(err)
+						}
+					}
+				}
+			},
+		},
+		{
+			Name: "spr/convert",
+			Func: func(b *testing.B) {
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					for _, file := range files {
+						for j := range file.Frames {
+							if _, err := file.ImageAt(j); err != nil {
+								log.Fatal(err)
+							}
+						}
+					}
+				}
+			},
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dataPath = "./../../data"
+)
+
+func names(benchmarks []benchmark) []string {
+	var out []string
+	for _, bb := range benchmarks {
+		out = append(out, bb.Name)
+	}
+
+	return out
+}
+
+func TestGRFBenchmarks(t *testing.T) {
+	benchmarks, err := grfBenchmarks(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"grf/open", "grf/read-all"}, names(benchmarks))
+}
+
+func TestSpriteBenchmarks(t *testing.T) {
+	valid := sprtest.Encode([]sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0x01}}})
+
+	// Version 2.0 sprites are not supported by spr.Load.
+	old := append([]byte(nil), valid...)
+	old[2] = 0
+
+	assert.Empty(t, spriteBenchmarks([][]byte{old}))
+
+	benchmarks := spriteBenchmarks([][]byte{old, valid})
+	assert.Equal(t, []string{"spr/load", "spr/convert"}, names(benchmarks))
+
+	benchTime := flag.Lookup("test.benchtime")
+	defer func(value string) { _ = benchTime.Value.Set(value) }(benchTime.Value.String())
+	assert.NoError(t, benchTime.Value.Set("1x"))
+
+	for _, bb := range benchmarks {
+		assert.Equal(t, 1, testing.Benchmark(bb.Func).N, bb.Name)
+	}
+}
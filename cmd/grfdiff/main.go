@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"golang.org/x/text/encoding/korean"
+)
+
+// hashLength is the number of hex digits of the SHA-1 printed per entry.
+const hashLength = 12
+
+// entry is the fingerprint of a file on one side of the comparison.
+type entry struct {
+	Name string
+	Size int
+	Hash string
+
+	// Err is set when the file could not be read. Such entries are always
+	// reported, since their contents cannot be compared.
+	Err error
+}
+
+// summary counts the differences found by diff.
+type summary struct {
+	Added, Removed, Changed, Errored int
+	Delta                            int
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: grfdiff old.grf|dir new.grf|dir")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	before, err := scan(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	after, err := scan(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	sum := diff(os.Stdout, before, after)
+	fmt.Printf("\n%d added, %d removed, %d changed, %d unreadable, %+d bytes\n",
+		sum.Added, sum.Removed, sum.Changed, sum.Errored, sum.Delta)
+
+	if sum.Added+sum.Removed+sum.Changed+sum.Errored > 0 {
+		os.Exit(1)
+	}
+}
+
+// diff writes one line per added (A), removed (D), modified (M) or unreadable
+// (E) file to w, in path order.
+func diff(w io.Writer, before, after map[string]entry) summary {
+	keys := make([]string, 0, len(before)+len(after))
+	for key := range before {
+		keys = append(keys, key)
+	}
+	for key := range after {
+		if _, ok := before[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var sum summary
+
+	for _, key := range keys {
+		old, inBefore := before[key]
+		cur, inAfter := after[key]
+
+		switch {
+		case old.Err != nil || cur.Err != nil:
+			sum.Errored++
+			for _, e := range []entry{old, cur} {
+				if e.Err != nil {
+					fmt.Fprintf(w, "E\t-\t%v\t%s\n", e.Err, e.Name)
+				}
+			}
+		case !inBefore:
+			sum.Added++
+			sum.Delta += cur.Size
+			fmt.Fprintf(w, "A\t%+d\t%s\t%s\n", cur.Size, cur.Hash, cur.Name)
+		case !inAfter:
+			sum.Removed++
+			sum.Delta -= old.Size
+			fmt.Fprintf(w, "D\t%+d\t%s\t%s\n", -old.Size, old.Hash, old.Name)
+		case old.Hash != cur.Hash || old.Size != cur.Size:
+			sum.Changed++
+			sum.Delta += cur.Size - old.Size
+			fmt.Fprintf(w, "M\t%+d\t%s..%s\t%s\n", cur.Size-old.Size, old.Hash, cur.Hash, cur.Name)
+		}
+	}
+
+	return sum
+}
+
+// scan fingerprints every file of a GRF or a directory, keyed by their
+// case-insensitive slash separated path.
+func scan(name string) (map[string]entry, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+
+	if fi.IsDir() {
+		return scanDir(os.DirFS(name))
+	}
+
+	return scanGRF(name)
+}
+
+func scanGRF(name string) (map[string]entry, error) {
+	f, err := grf.NewFile(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := make(map[string]entry, len(f.GetEntries()))
+	decoder := korean.EUCKR.NewDecoder()
+
+	for raw := range f.GetEntries() {
+		path, err := decoder.String(strings.ReplaceAll(raw, "\\", "/"))
+		if err != nil {
+			return nil, err
+		}
+
+		e := entry{Name: path}
+
+		if data, err := f.ReadFile(raw); err != nil {
+			e.Err = err
+		} else {
+			e.Size = len(data)
+			e.Hash = hash(data)
+		}
+
+		entries[strings.ToLower(path)] = e
+	}
+
+	return entries, nil
+}
+
+func scanDir(fsys fs.FS) (map[string]entry, error) {
+	entries := make(map[string]entry)
+
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+
+		entries[strings.ToLower(path)] = entry{Name: path, Size: len(data), Hash: hash(data)}
+
+		return nil
+	})
+
+	return entries, err
+}
+
+func hash(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])[:hashLength]
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dataPath = "./../../data"
+)
+
+func TestDiff(t *testing.T) {
+	unreadable := errors.New("zlib: invalid header")
+
+	before := map[string]entry{
+		"same":       {Name: "same", Size: 1, Hash: "aaa"},
+		"changed":    {Name: "changed", Size: 1, Hash: "bbb"},
+		"removed":    {Name: "removed", Size: 2, Hash: "ccc"},
+		"unreadable": {Name: "unreadable", Err: unreadable},
+	}
+	after := map[string]entry{
+		"same":       {Name: "same", Size: 1, Hash: "aaa"},
+		"changed":    {Name: "changed", Size: 4, Hash: "ddd"},
+		"added":      {Name: "added", Size: 8, Hash: "eee"},
+		"unreadable": {Name: "unreadable", Err: unreadable},
+	}
+
+	out := new(bytes.Buffer)
+	sum := diff(out, before, after)
+
+	assert.Equal(t, summary{Added: 1, Removed: 1, Changed: 1, Errored: 1, Delta: 9}, sum)
+	assert.Equal(t, "A\t+8\teee\tadded\n"+
+		"M\t+3\tbbb..ddd\tchanged\n"+
+		"D\t-2\tccc\tremoved\n"+
+		"E\t-\tzlib: invalid header\tunreadable\n"+
+		"E\t-\tzlib: invalid header\tunreadable\n", out.String())
+}
+
+func TestScanUnreadableEntries(t *testing.T) {
+	path := fmt.Sprintf("%s/%s", dataPath, "with-files.grf")
+
+	before, err := scan(path)
+	assert.NoError(t, err)
+
+	after, err := scan(path)
+	assert.NoError(t, err)
+
+	assert.Error(t, before["corrupted"].Err)
+
+	sum := diff(new(bytes.Buffer), before, after)
+	assert.Equal(t, summary{Errored: 1}, sum)
+}
+
+func TestScanDir(t *testing.T) {
+	entries, err := scanDir(fstest.MapFS{
+		"data/Sprite/test.spr": {Data: []byte("sprite")},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]entry{
+		"data/sprite/test.spr": {Name: "data/Sprite/test.spr", Size: 6, Hash: hash([]byte("sprite"))},
+	}, entries)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/project-midgard/midgarts/fileformat/pal"
+	"github.com/project-midgard/midgarts/fileformat/spr"
+)
+
+// padding is the transparent gap, in pixels, between two cells of the sheet.
+const padding = 2
+
+func main() {
+	frame := flag.Int("frame", 0, "sprite frame to render")
+	columns := flag.Int("columns", 8, "palettes per row")
+	out := flag.String("o", "sheet.png", "output PNG file")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: paltool [-frame 0] [-columns 8] [-o sheet.png] sprite.spr palettes/")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 2 || *columns < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	sprite, err := loadSprite(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	names, err := filepath.Glob(filepath.Join(flag.Arg(1), "*.pal"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	sort.Strings(names)
+
+	var cells []*image.NRGBA
+	for _, name := range names {
+		p, err := loadPalette(name)
+		if err != nil {
+			log.Printf("skipping %s: %v\n", name, err)
+			continue
+		}
+
+		img, err := sprite.WithPalette(p).ImageAt(*frame)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("%d\t%s\n", len(cells), filepath.Base(name))
+		cells = append(cells, img)
+	}
+
+	if len(cells) == 0 {
+		log.Fatalf("no palettes found in %s\n", flag.Arg(1))
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	if err = png.Encode(f, contactSheet(cells, *columns)); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("wrote %s (%d palettes)\n", *out, len(cells))
+}
+
+// contactSheet lays out cells, which all have the same size, in rows of
+// columns images from left to right.
+func contactSheet(cells []*image.NRGBA, columns int) *image.NRGBA {
+	if columns > len(cells) {
+		columns = len(cells)
+	}
+	rows := (len(cells) + columns - 1) / columns
+
+	size := cells[0].Bounds().Size()
+	cell := size.Add(image.Pt(padding, padding))
+	sheet := image.NewNRGBA(image.Rect(0, 0, columns*cell.X-padding, rows*cell.Y-padding))
+
+	for i, img := range cells {
+		at := image.Pt(i%columns*cell.X, i/columns*cell.Y)
+		draw.Draw(sheet, image.Rectangle{Min: at, Max: at.Add(size)}, img, img.Bounds().Min, draw.Src)
+	}
+
+	return sheet
+}
+
+func loadSprite(name string) (*spr.SpriteFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return spr.Load(f)
+}
+
+func loadPalette(name string) (*pal.Palette, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return pal.Load(f)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/pal"
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContactSheet(t *testing.T) {
+	var cells []*image.NRGBA
+	for _, c := range []color.NRGBA{{R: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {B: 0xff, A: 0xff}} {
+		img := image.NewNRGBA(image.Rect(0, 0, 2, 1))
+		img.SetNRGBA(0, 0, c)
+		img.SetNRGBA(1, 0, c)
+		cells = append(cells, img)
+	}
+
+	sheet := contactSheet(cells, 2)
+	assert.Equal(t, image.Rect(0, 0, 2*2+padding, 2*1+padding), sheet.Bounds())
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, sheet.NRGBAAt(1, 0))
+	assert.Equal(t, color.NRGBA{}, sheet.NRGBAAt(2, 0))
+	assert.Equal(t, color.NRGBA{G: 0xff, A: 0xff}, sheet.NRGBAAt(2+padding, 0))
+	assert.Equal(t, color.NRGBA{B: 0xff, A: 0xff}, sheet.NRGBAAt(0, 1+padding))
+
+	sheet = contactSheet(cells, 8)
+	assert.Equal(t, image.Rect(0, 0, 3*(2+padding)-padding, 1), sheet.Bounds())
+}
+
+func TestRenderWithPalette(t *testing.T) {
+	dir, err := ioutil.TempDir("", "paltool")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	spritePath := filepath.Join(dir, "test.spr")
+	assert.NoError(t, ioutil.WriteFile(spritePath, sprtest.Encode([]sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0x01}}}), 0644))
+
+	palette := make([]byte, pal.FileSize)
+	copy(palette[4:], []byte{0x00, 0xff, 0x00})
+	palettePath := filepath.Join(dir, "green.pal")
+	assert.NoError(t, ioutil.WriteFile(palettePath, palette, 0644))
+
+	sprite, err := loadSprite(spritePath)
+	assert.NoError(t, err)
+
+	p, err := loadPalette(palettePath)
+	assert.NoError(t, err)
+
+	img, err := sprite.WithPalette(p).ImageAt(0)
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{G: 0xff, A: 0xff}, img.NRGBAAt(0, 0))
+
+	_, err = loadPalette(spritePath + ".missing")
+	assert.Error(t, err)
+}
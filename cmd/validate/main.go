@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/project-midgard/midgarts/fileformat/spr"
+)
+
+// parsers maps asset extensions to a strict parse of their contents.
+var parsers = map[string]func(r io.Reader) error{
+	".spr": func(r io.Reader) error {
+		_, err := spr.LoadWithOptions(r, spr.LoadOptions{Strict: true})
+		return err
+	},
+}
+
+// pending lists the asset extensions that are scanned but have no parser yet.
+var pending = []string{".act", ".rsm", ".gnd", ".gat", ".rsw"}
+
+func main() {
+	verbose := flag.Bool("v", false, "also print files that parsed successfully")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "usage: validate [-v] file.grf")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := grf.NewFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	names := make([]string, 0, len(f.GetEntries()))
+	for name := range f.GetEntries() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var (
+		checked, failed int
+		skipped         = make(map[string]int)
+	)
+
+	for _, name := range names {
+		ext := strings.ToLower(path.Ext(name))
+
+		parse, ok := parsers[ext]
+		if !ok {
+			if isPending(ext) {
+				skipped[ext]++
+			}
+			continue
+		}
+
+		checked++
+
+		e, err := f.GetEntry(name)
+		if err != nil {
+			failed++
+			fmt.Printf("FAIL\t%s\t-\t%v\n", name, err)
+			continue
+		}
+
+		if offset, err := check(parse, e.Data.Bytes()); err != nil {
+			failed++
+			fmt.Printf("FAIL\t%s\t0x%x\t%v\n", name, offset, err)
+			continue
+		}
+
+		if *verbose {
+			fmt.Printf("OK\t%s\n", name)
+		}
+	}
+
+	fmt.Printf("\n%d files checked, %d failed\n", checked, failed)
+	for _, ext := range pending {
+		if skipped[ext] > 0 {
+			fmt.Printf("%d %s files skipped, no parser available\n", skipped[ext], ext)
+		}
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// check parses data and, on failure, returns the offset of the bad data: where
+// the parser stopped reading, or where trailing data starts.
+func check(parse func(r io.Reader) error, data []byte) (int64, error) {
+	r := bytes.NewReader(data)
+
+	err := parse(r)
+	if err == nil {
+		return 0, nil
+	}
+
+	var trailing *spr.TrailingDataError
+	if errors.As(err, &trailing) {
+		return trailing.Offset, err
+	}
+
+	return r.Size() - int64(r.Len()), err
+}
+
+func isPending(ext string) bool {
+	for _, e := range pending {
+		if e == ext {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	sprite := sprtest.Encode([]sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0x01}}})
+
+	var tests = []struct {
+		Name           string
+		Data           []byte
+		ExpectedOffset int64
+		ExpectedError  bool
+	}{
+		{
+			Name: "valid sprite",
+			Data: sprite,
+		},
+		{
+			Name:           "truncated sprite",
+			Data:           sprite[:len(sprite)-10],
+			ExpectedOffset: int64(len(sprite) - 10),
+			ExpectedError:  true,
+		},
+		{
+			Name:           "sprite with trailing data",
+			Data:           append(append([]byte(nil), sprite...), 0x00, 0x00),
+			ExpectedOffset: int64(len(sprite)),
+			ExpectedError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			offset, err := check(parsers[".spr"], tt.Data)
+			assert.Equal(t, tt.ExpectedError, err != nil)
+			assert.Equal(t, tt.ExpectedOffset, offset)
+		})
+	}
+}
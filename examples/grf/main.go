@@ -1,12 +1,16 @@
 package main
 
 import (
+	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
+	"path/filepath"
 	"strings"
 
 	"github.com/project-midgard/midgarts/fileformat/grf"
+	"golang.org/x/text/encoding/korean"
 )
 
 func main() {
@@ -15,6 +19,16 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if len(os.Args) > 2 && os.Args[2] == "-x" {
+		var pattern string
+		if len(os.Args) > 3 {
+			pattern = os.Args[3]
+		}
+
+		extract(f, pattern)
+		return
+	}
+
 	if len(os.Args) > 2 {
 		e, err := f.GetEntry(os.Args[2])
 		if err != nil {
@@ -31,3 +45,46 @@ func main() {
 		}
 	}
 }
+
+// extract writes the entries matching pattern, e.g. "data/sprite/*/*.spr",
+// under the current directory, with UTF-8 file names.
+func extract(f *grf.File, pattern string) {
+	err := f.Extract(grf.ExtractOptions{
+		Pattern: pattern,
+		Progress: func(done, total int) {
+			fmt.Printf("\r%d/%d", done, total)
+		},
+	}, func(name string, data []byte) error {
+		path, err := outputPath(name)
+		if err != nil {
+			return err
+		}
+
+		if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(path, data, 0644)
+	})
+	fmt.Println()
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// outputPath converts an entry name into a UTF-8 path relative to the current
+// directory. Names that would land outside of it are rejected.
+func outputPath(name string) (string, error) {
+	decoded, err := korean.EUCKR.NewDecoder().String(name)
+	if err != nil {
+		return "", err
+	}
+
+	p := path.Clean(strings.ReplaceAll(decoded, "\\", "/"))
+	if path.IsAbs(p) || filepath.IsAbs(filepath.FromSlash(p)) || p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("entry '%s' is outside the output directory", decoded)
+	}
+
+	return filepath.FromSlash(p), nil
+}
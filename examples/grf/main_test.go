@@ -0,0 +1,66 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutputPath(t *testing.T) {
+	var tests = []struct {
+		Name          string
+		EntryName     string
+		ExpectedPath  string
+		ExpectedError bool
+	}{
+		{
+			Name:         "relative entry",
+			EntryName:    "data\\sprite\\test.spr",
+			ExpectedPath: "data/sprite/test.spr",
+		},
+		{
+			Name:         "EUC-KR entry",
+			EntryName:    "data\\\xc0\xaf\xc0\xfa\\test.bmp",
+			ExpectedPath: "data/유저/test.bmp",
+		},
+		{
+			Name:         "parent directory inside the output",
+			EntryName:    "data\\sprite\\..\\test.spr",
+			ExpectedPath: "data/test.spr",
+		},
+		{
+			Name:          "parent directory traversal",
+			EntryName:     "..\\..\\home\\user\\.bashrc",
+			ExpectedError: true,
+		},
+		{
+			Name:          "nested parent directory traversal",
+			EntryName:     "data\\..\\..\\x",
+			ExpectedError: true,
+		},
+		{
+			Name:          "parent directory",
+			EntryName:     "data\\..\\..",
+			ExpectedError: true,
+		},
+		{
+			Name:          "absolute path",
+			EntryName:     "\\etc\\cron.d\\x",
+			ExpectedError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			actual, err := outputPath(tt.EntryName)
+			if tt.ExpectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, filepath.FromSlash(tt.ExpectedPath), actual)
+		})
+	}
+}
@@ -0,0 +1,315 @@
+package bmp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	HeaderSignature = "BM"
+
+	fileHeaderLength     = 14
+	coreInfoHeaderLength = 12
+	infoHeaderLength     = 40
+	v4InfoHeaderLength   = 108
+
+	compressionRGB       = 0
+	compressionBitFields = 3
+)
+
+// ColorKey is the color the original client renders as fully transparent.
+var ColorKey = color.NRGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}
+
+// File is a decoded Windows bitmap.
+type File struct {
+	Header struct {
+		Signature  [2]byte
+		FileSize   uint32
+		Reserved   uint32
+		DataOffset uint32
+	}
+
+	InfoHeader struct {
+		Size            uint32
+		Width           int32
+		Height          int32
+		Planes          uint16
+		BitCount        uint16
+		Compression     uint32
+		ImageSize       uint32
+		XPelsPerMeter   int32
+		YPelsPerMeter   int32
+		ColorsUsed      uint32
+		ColorsImportant uint32
+	}
+
+	Palette []color.NRGBA
+	Image   *image.NRGBA
+}
+
+// Load decodes an uncompressed BMP image. Pixels matching ColorKey are made
+// fully transparent.
+func Load(buf io.Reader) (*File, error) {
+	data, err := ioutil.ReadAll(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read file")
+	}
+
+	file := new(File)
+	if err = file.parseHeader(data); err != nil {
+		return nil, err
+	}
+
+	if err = file.parsePalette(data); err != nil {
+		return nil, err
+	}
+
+	if err = file.parsePixels(data); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (f *File) parseHeader(data []byte) error {
+	reader := bytes.NewReader(data)
+
+	if err := binary.Read(reader, binary.LittleEndian, &f.Header); err != nil {
+		return errors.Wrap(err, "could not read file header")
+	}
+
+	if string(f.Header.Signature[:]) != HeaderSignature {
+		return fmt.Errorf("invalid signature: %s", f.Header.Signature)
+	}
+
+	if len(data) < fileHeaderLength+4 {
+		return errors.New("could not read info header")
+	}
+
+	size := binary.LittleEndian.Uint32(data[fileHeaderLength:])
+	switch {
+	case size == coreInfoHeaderLength:
+		var core struct {
+			Size     uint32
+			Width    int16
+			Height   int16
+			Planes   uint16
+			BitCount uint16
+		}
+
+		if err := binary.Read(reader, binary.LittleEndian, &core); err != nil {
+			return errors.Wrap(err, "could not read core info header")
+		}
+
+		f.InfoHeader.Size = core.Size
+		f.InfoHeader.Width = int32(core.Width)
+		f.InfoHeader.Height = int32(core.Height)
+		f.InfoHeader.Planes = core.Planes
+		f.InfoHeader.BitCount = core.BitCount
+	case size >= infoHeaderLength:
+		if err := binary.Read(reader, binary.LittleEndian, &f.InfoHeader); err != nil {
+			return errors.Wrap(err, "could not read info header")
+		}
+	default:
+		return fmt.Errorf("unsupported info header size %d", size)
+	}
+
+	if f.InfoHeader.Width <= 0 || f.InfoHeader.Height == 0 {
+		return fmt.Errorf("invalid dimensions %dx%d", f.InfoHeader.Width, f.InfoHeader.Height)
+	}
+
+	switch f.InfoHeader.Compression {
+	case compressionRGB:
+	case compressionBitFields:
+		if f.InfoHeader.BitCount != 16 && f.InfoHeader.BitCount != 32 {
+			return fmt.Errorf("unsupported bit fields with %d bits per pixel", f.InfoHeader.BitCount)
+		}
+	default:
+		return fmt.Errorf("unsupported compression %d", f.InfoHeader.Compression)
+	}
+
+	return nil
+}
+
+func (f *File) parsePalette(data []byte) error {
+	if f.InfoHeader.BitCount > 8 {
+		return nil
+	}
+
+	var (
+		offset    = fileHeaderLength + int(f.InfoHeader.Size)
+		entrySize = 4
+		count     = int(f.InfoHeader.ColorsUsed)
+	)
+
+	if f.InfoHeader.Size == coreInfoHeaderLength {
+		entrySize = 3
+	}
+
+	if count == 0 || count > 1<<f.InfoHeader.BitCount {
+		count = 1 << f.InfoHeader.BitCount
+	}
+
+	if offset+count*entrySize > len(data) {
+		return errors.New("could not read palette")
+	}
+
+	f.Palette = make([]color.NRGBA, count)
+	for i := range f.Palette {
+		entry := data[offset+i*entrySize:]
+		f.Palette[i] = color.NRGBA{R: entry[2], G: entry[1], B: entry[0], A: 0xff}
+	}
+
+	return nil
+}
+
+func (f *File) parsePixels(data []byte) error {
+	var (
+		width    = int(f.InfoHeader.Width)
+		height   = int(f.InfoHeader.Height)
+		bitCount = int(f.InfoHeader.BitCount)
+		topDown  = height < 0
+	)
+
+	if topDown {
+		height = -height
+	}
+
+	var (
+		stride = ((width*bitCount + 31) / 32) * 4
+		offset = int(f.Header.DataOffset)
+	)
+
+	if offset < fileHeaderLength || offset+stride*height > len(data) {
+		return errors.New("could not read pixel data")
+	}
+
+	masks, err := f.channelMasks(data)
+	if err != nil {
+		return err
+	}
+
+	f.Image = image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		row := data[offset+y*stride : offset+(y+1)*stride]
+
+		dy := height - y - 1
+		if topDown {
+			dy = y
+		}
+
+		for x := 0; x < width; x++ {
+			var c color.NRGBA
+
+			switch bitCount {
+			case 1, 2, 4, 8:
+				bit := x * bitCount
+				index := int(row[bit/8]>>(8-bitCount-bit%8)) & (1<<bitCount - 1)
+				if index >= len(f.Palette) {
+					return fmt.Errorf("palette index %d out of range", index)
+				}
+				c = f.Palette[index]
+			case 16:
+				c = masks.decode(uint32(binary.LittleEndian.Uint16(row[x*2:])))
+			case 24:
+				c = color.NRGBA{R: row[x*3+2], G: row[x*3+1], B: row[x*3], A: 0xff}
+			case 32:
+				c = masks.decode(binary.LittleEndian.Uint32(row[x*4:]))
+			default:
+				return fmt.Errorf("unsupported bit count %d", bitCount)
+			}
+
+			if c == ColorKey {
+				c = color.NRGBA{}
+			}
+
+			f.Image.SetNRGBA(x, dy, c)
+		}
+	}
+
+	return nil
+}
+
+// channelMasks returns the masks used to unpack 16 and 32 bits pixels.
+func (f *File) channelMasks(data []byte) (channelMasks, error) {
+	switch {
+	case f.InfoHeader.Compression == compressionBitFields:
+		const offset = fileHeaderLength + infoHeaderLength
+
+		if len(data) < offset+12 {
+			return channelMasks{}, errors.New("could not read bit field masks")
+		}
+
+		masks := channelMasks{
+			binary.LittleEndian.Uint32(data[offset:]),
+			binary.LittleEndian.Uint32(data[offset+4:]),
+			binary.LittleEndian.Uint32(data[offset+8:]),
+			0,
+		}
+
+		if f.InfoHeader.Size >= v4InfoHeaderLength {
+			if len(data) < offset+16 {
+				return channelMasks{}, errors.New("could not read alpha mask")
+			}
+
+			masks[3] = binary.LittleEndian.Uint32(data[offset+12:])
+		}
+
+		return masks, nil
+	case f.InfoHeader.BitCount == 16:
+		return channelMasks{0x7c00, 0x03e0, 0x001f, 0}, nil
+	default:
+		return channelMasks{0x00ff0000, 0x0000ff00, 0x000000ff, 0}, nil
+	}
+}
+
+// channelMasks holds the red, green, blue and alpha bit masks of a pixel.
+type channelMasks [4]uint32
+
+func (m channelMasks) decode(v uint32) color.NRGBA {
+	c := color.NRGBA{
+		R: extractChannel(v, m[0]),
+		G: extractChannel(v, m[1]),
+		B: extractChannel(v, m[2]),
+		A: 0xff,
+	}
+
+	if m[3] != 0 {
+		c.A = extractChannel(v, m[3])
+	}
+
+	return c
+}
+
+// extractChannel scales the masked bits of v to the 0-255 range.
+func extractChannel(v, mask uint32) byte {
+	if mask == 0 {
+		return 0
+	}
+
+	shift := uint(0)
+	for mask&1 == 0 {
+		mask >>= 1
+		shift++
+	}
+
+	bits := uint(0)
+	for mask>>bits&1 == 1 {
+		bits++
+	}
+
+	value := (v >> shift) & mask
+	if bits >= 8 {
+		return byte(value >> (bits - 8))
+	}
+
+	return byte(value * 0xff / mask)
+}
@@ -0,0 +1,126 @@
+package bmp_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/bmp"
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dataPath = "./../../data"
+)
+
+// encode builds a bottom-up BMP with the given bit count, palette and rows.
+func encode(width, height int32, bitCount uint16, palette []byte, rows []byte) []byte {
+	buf := new(bytes.Buffer)
+	dataOffset := uint32(14 + 40 + len(palette))
+
+	buf.WriteString("BM")
+	_ = binary.Write(buf, binary.LittleEndian, []uint32{dataOffset + uint32(len(rows)), 0, dataOffset})
+	_ = binary.Write(buf, binary.LittleEndian, struct {
+		Size          uint32
+		Width, Height int32
+		Planes        uint16
+		BitCount      uint16
+		Rest          [6]uint32
+	}{Size: 40, Width: width, Height: height, Planes: 1, BitCount: bitCount})
+	buf.Write(palette)
+	buf.Write(rows)
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	var (
+		transparent = color.NRGBA{}
+		red         = color.NRGBA{R: 0xff, A: 0xff}
+		green       = color.NRGBA{G: 0xff, A: 0xff}
+		nearMagenta = color.NRGBA{R: 0xfe, B: 0xff, A: 0xff}
+	)
+
+	var tests = []struct {
+		Name     string
+		Data     []byte
+		Expected [2]color.NRGBA
+	}{
+		{
+			Name: "load 24 bits image",
+			Data: encode(2, 1, 24, nil, []byte{
+				0x00, 0x00, 0xff, 0x00, 0xff, 0x00, 0x00, 0x00,
+			}),
+			Expected: [2]color.NRGBA{red, green},
+		},
+		{
+			Name: "load 24 bits image with magenta color key",
+			Data: encode(2, 1, 24, nil, []byte{
+				0xff, 0x00, 0xff, 0xff, 0x00, 0xfe, 0x00, 0x00,
+			}),
+			Expected: [2]color.NRGBA{transparent, nearMagenta},
+		},
+		{
+			Name: "load 8 bits image with magenta palette entry",
+			Data: encode(2, 1, 8, append(
+				[]byte{0xff, 0x00, 0xff, 0x00, 0x00, 0x00, 0xff, 0x00},
+				make([]byte, 254*4)...,
+			), []byte{0x01, 0x00, 0x00, 0x00}),
+			Expected: [2]color.NRGBA{red, transparent},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			file, err := bmp.Load(bytes.NewReader(tt.Data))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected[0], file.Image.NRGBAAt(0, 0))
+			assert.Equal(t, tt.Expected[1], file.Image.NRGBAAt(1, 0))
+		})
+	}
+}
+
+func TestLoadFromGRF(t *testing.T) {
+	grfFile, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "custom.grf"))
+	assert.NoError(t, err)
+
+	entry, err := grfFile.GetEntry("data\\0_Tex1.bmp")
+	assert.NoError(t, err)
+
+	file, err := bmp.Load(entry.Data)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(24), file.InfoHeader.BitCount)
+	assert.Equal(t, 256, file.Image.Bounds().Dx())
+	assert.Equal(t, 256, file.Image.Bounds().Dy())
+}
+
+func TestLoadErrors(t *testing.T) {
+	var tests = []struct {
+		Name string
+		Data []byte
+	}{
+		{Name: "invalid signature", Data: []byte("XX this is not a bitmap file at all")},
+		{Name: "truncated pixel data", Data: encode(4, 4, 24, nil, []byte{0x00})},
+		{Name: "unsupported compression", Data: func() []byte {
+			data := encode(1, 1, 8, make([]byte, 256*4), []byte{0, 0, 0, 0})
+			data[30] = 1
+			return data
+		}()},
+		{Name: "truncated v4 alpha mask", Data: func() []byte {
+			data := encode(1, 1, 32, nil, make([]byte, 12))
+			data[14] = 108
+			data[30] = 3
+			return data
+		}()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := bmp.Load(bytes.NewReader(tt.Data))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package clientinfo
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/transform"
+)
+
+// Connection is a single server entry of a clientinfo file.
+type Connection struct {
+	Display         string   `xml:"display"`
+	Description     string   `xml:"desc"`
+	Balloon         string   `xml:"balloon"`
+	Address         string   `xml:"address"`
+	Port            int      `xml:"port"`
+	Version         int      `xml:"version"`
+	LangType        int      `xml:"langtype"`
+	RegistrationWeb string   `xml:"registrationweb"`
+	LoadingImages   []string `xml:"loading>image"`
+	Admins          []int    `xml:"yellow>admin"`
+}
+
+// Addr returns the login server address in host:port form.
+func (c Connection) Addr() string {
+	return net.JoinHostPort(c.Address, strconv.Itoa(c.Port))
+}
+
+// File holds the server list distributed as clientinfo.xml or sclientinfo.xml.
+type File struct {
+	XMLName     xml.Name     `xml:"clientinfo"`
+	ServiceType string       `xml:"servicetype"`
+	ServerType  string       `xml:"servertype"`
+	Connections []Connection `xml:"connection"`
+}
+
+// Load parses a clientinfo file. Text declared in a legacy encoding such as
+// EUC-KR is decoded to UTF-8.
+func Load(buf io.Reader) (*File, error) {
+	file := new(File)
+
+	decoder := xml.NewDecoder(buf)
+	decoder.Strict = false
+	decoder.CharsetReader = charsetReader
+
+	if err := decoder.Decode(file); err != nil {
+		return nil, errors.Wrap(err, "could not decode clientinfo")
+	}
+
+	for i := range file.Connections {
+		c := &file.Connections[i]
+		c.Display = strings.TrimSpace(c.Display)
+		c.Address = strings.TrimSpace(c.Address)
+	}
+
+	if len(file.Connections) == 0 {
+		return nil, errors.New("no connection entries found")
+	}
+
+	return file, nil
+}
+
+// charsetReader decodes input from the IANA registered charset to UTF-8.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	enc, err := ianaindex.IANA.Encoding(charset)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unknown charset %s", charset)
+	}
+
+	if enc == nil {
+		return nil, fmt.Errorf("unsupported charset %s", charset)
+	}
+
+	return transform.NewReader(input, enc.NewDecoder()), nil
+}
@@ -0,0 +1,94 @@
+package clientinfo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/clientinfo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	const data = `<?xml version="1.0" encoding="euc-kr" ?>
+<clientinfo>
+	<servicetype>america</servicetype>
+	<servertype>primary</servertype>
+	<connection>
+		<display> Midgard </display>
+		<desc>Main server</desc>
+		<address>127.0.0.1</address>
+		<port>6900</port>
+		<version>55</version>
+		<langtype>1</langtype>
+		<registrationweb>www.example.com</registrationweb>
+		<loading>
+			<image>loading00.jpg</image>
+			<image>loading01.jpg</image>
+		</loading>
+		<yellow>
+			<admin>2000000</admin>
+		</yellow>
+	</connection>
+	<connection>
+		<display>Test</display>
+		<address>10.0.0.2</address>
+		<port>7000</port>
+		<version>46</version>
+		<langtype>0</langtype>
+	</connection>
+</clientinfo>`
+
+	file, err := clientinfo.Load(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "america", file.ServiceType)
+	assert.Equal(t, "primary", file.ServerType)
+	assert.Equal(t, []clientinfo.Connection{
+		{
+			Display:         "Midgard",
+			Description:     "Main server",
+			Address:         "127.0.0.1",
+			Port:            6900,
+			Version:         55,
+			LangType:        1,
+			RegistrationWeb: "www.example.com",
+			LoadingImages:   []string{"loading00.jpg", "loading01.jpg"},
+			Admins:          []int{2000000},
+		},
+		{
+			Display:  "Test",
+			Address:  "10.0.0.2",
+			Port:     7000,
+			Version:  46,
+			LangType: 0,
+		},
+	}, file.Connections)
+	assert.Equal(t, "127.0.0.1:6900", file.Connections[0].Addr())
+}
+
+func TestLoadEUCKR(t *testing.T) {
+	const data = "<?xml version=\"1.0\" encoding=\"euc-kr\" ?>\n" +
+		"<clientinfo><connection><display>\xc0\xaf\xc0\xfa</display><address>127.0.0.1</address></connection></clientinfo>"
+
+	file, err := clientinfo.Load(strings.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, "유저", file.Connections[0].Display)
+}
+
+func TestLoadErrors(t *testing.T) {
+	var tests = []struct {
+		Name string
+		Data string
+	}{
+		{Name: "not xml", Data: "This is a text file"},
+		{Name: "no connections", Data: "<clientinfo><servicetype>korea</servicetype></clientinfo>"},
+		{Name: "invalid port", Data: "<clientinfo><connection><port>abc</port></connection></clientinfo>"},
+		{Name: "unknown charset", Data: `<?xml version="1.0" encoding="x-unknown" ?><clientinfo><connection/></clientinfo>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := clientinfo.Load(strings.NewReader(tt.Data))
+			assert.Error(t, err)
+		})
+	}
+}
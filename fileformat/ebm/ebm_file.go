@@ -0,0 +1,56 @@
+package ebm
+
+import (
+	"bytes"
+	"compress/zlib"
+	"image"
+	"image/draw"
+	"image/gif"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/bmp"
+)
+
+const (
+	gifSignature = "GIF8"
+)
+
+// File is a decoded guild emblem.
+type File struct {
+	Image *image.NRGBA
+}
+
+// Load decodes a zlib-compressed guild emblem. Emblems are usually bitmaps
+// keyed on magenta, but newer servers also accept GIF images.
+func Load(buf io.Reader) (*File, error) {
+	zlibReader, err := zlib.NewReader(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read emblem")
+	}
+	defer zlibReader.Close()
+
+	data := new(bytes.Buffer)
+	if _, err = io.Copy(data, zlibReader); err != nil {
+		return nil, errors.Wrap(err, "could not decompress emblem")
+	}
+
+	if bytes.HasPrefix(data.Bytes(), []byte(gifSignature)) {
+		img, err := gif.Decode(data)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not decode gif emblem")
+		}
+
+		out := image.NewNRGBA(img.Bounds())
+		draw.Draw(out, out.Bounds(), img, img.Bounds().Min, draw.Src)
+
+		return &File{Image: out}, nil
+	}
+
+	bmpFile, err := bmp.Load(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode bitmap emblem")
+	}
+
+	return &File{Image: bmpFile.Image}, nil
+}
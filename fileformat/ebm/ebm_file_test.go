@@ -0,0 +1,67 @@
+package ebm_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/ebm"
+	"github.com/stretchr/testify/assert"
+)
+
+func compress(data []byte) []byte {
+	buf := new(bytes.Buffer)
+	w := zlib.NewWriter(buf)
+	_, _ = w.Write(data)
+	_ = w.Close()
+
+	return buf.Bytes()
+}
+
+// bitmap builds a 2x1 24 bits BMP with a red and a magenta pixel.
+func bitmap() []byte {
+	buf := new(bytes.Buffer)
+
+	buf.WriteString("BM")
+	_ = binary.Write(buf, binary.LittleEndian, []uint32{62, 0, 54})
+	_ = binary.Write(buf, binary.LittleEndian, []uint32{40, 2, 1})
+	_ = binary.Write(buf, binary.LittleEndian, []uint16{1, 24})
+	_ = binary.Write(buf, binary.LittleEndian, make([]uint32, 6))
+	buf.Write([]byte{0x00, 0x00, 0xff, 0xff, 0x00, 0xff, 0x00, 0x00})
+
+	return buf.Bytes()
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("load bitmap emblem", func(t *testing.T) {
+		file, err := ebm.Load(bytes.NewReader(compress(bitmap())))
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, file.Image.NRGBAAt(0, 0))
+		assert.Equal(t, color.NRGBA{}, file.Image.NRGBAAt(1, 0))
+	})
+
+	t.Run("load gif emblem", func(t *testing.T) {
+		img := image.NewPaletted(image.Rect(0, 0, 2, 1), color.Palette{
+			color.RGBA{G: 0xff, A: 0xff},
+			color.RGBA{B: 0xff, A: 0xff},
+		})
+		img.SetColorIndex(1, 0, 1)
+
+		data := new(bytes.Buffer)
+		assert.NoError(t, gif.Encode(data, img, nil))
+
+		file, err := ebm.Load(bytes.NewReader(compress(data.Bytes())))
+		assert.NoError(t, err)
+		assert.Equal(t, color.NRGBA{G: 0xff, A: 0xff}, file.Image.NRGBAAt(0, 0))
+		assert.Equal(t, color.NRGBA{B: 0xff, A: 0xff}, file.Image.NRGBAAt(1, 0))
+	})
+
+	t.Run("reject uncompressed data", func(t *testing.T) {
+		_, err := ebm.Load(bytes.NewReader(bitmap()))
+		assert.Error(t, err)
+	})
+}
@@ -3,10 +3,7 @@ package des
 import "strconv"
 
 var (
-	mask  = [8]byte{0x80, 0x40, 0x20, 0x10, 0x08, 0x04, 0x02, 0x01}
-	tmp2  = make([]byte, 8)
-	tmp   = make([]byte, 8)
-	clean = make([]byte, 8)
+	mask = [8]byte{0x80, 0x40, 0x20, 0x10, 0x08, 0x04, 0x02, 0x01}
 
 	initialPermutationTable = []byte{
 		58, 50, 42, 34, 26, 18, 10, 2,
@@ -123,6 +120,14 @@ func DecodeHeader(src []byte) {
 	}
 }
 
+// DecodeBlocks decrypts every block of src, as done for the file names of
+// 0x1xx archives.
+func DecodeBlocks(src []byte) {
+	for i := 0; i+8 <= len(src); i += 8 {
+		decryptBlock(src, i)
+	}
+}
+
 func decryptBlock(src []byte, index int) {
 	initialPermutation(src, index)
 	roundFunction(src, index)
@@ -130,7 +135,10 @@ func decryptBlock(src []byte, index int) {
 }
 
 func initialPermutation(src []byte, index int) {
-	var j int
+	var (
+		j   int
+		tmp [8]byte
+	)
 
 	for i := 0; i < 64; i++ {
 		j = int(initialPermutationTable[i]) - 1
@@ -139,18 +147,16 @@ func initialPermutation(src []byte, index int) {
 		}
 	}
 
-	appendAt(src, tmp, index)
-	appendAt(tmp, clean, 0)
+	appendAt(src, tmp[:], index)
 }
 
 func roundFunction(src []byte, index int) {
-	for i := 0; i < 8; i++ {
-		tmp2[i] = src[index+i]
-	}
+	var tmp2 [8]byte
+	copy(tmp2[:], src[index:index+8])
 
-	expansion(tmp2, 0)
-	substitutionBox(tmp2, 0)
-	transposition(tmp2, 0)
+	expansion(tmp2[:], 0)
+	substitutionBox(tmp2[:], 0)
+	transposition(tmp2[:], 0)
 
 	src[index+0] ^= tmp2[4]
 	src[index+1] ^= tmp2[5]
@@ -159,7 +165,10 @@ func roundFunction(src []byte, index int) {
 }
 
 func finalPermutation(src []byte, index int) {
-	var j int
+	var (
+		j   int
+		tmp [8]byte
+	)
 
 	for i := 0; i < 64; i++ {
 		j = int(finalPermutationTable[i]) - 1
@@ -168,12 +177,14 @@ func finalPermutation(src []byte, index int) {
 		}
 	}
 
-	appendAt(src, tmp, index)
-	appendAt(tmp, clean, 0)
+	appendAt(src, tmp[:], index)
 }
 
 func transposition(src []byte, index int) {
-	var j int
+	var (
+		j   int
+		tmp [8]byte
+	)
 
 	for i := 0; i < 32; i++ {
 		j = int(transpositionTable[i]) - 1
@@ -182,21 +193,23 @@ func transposition(src []byte, index int) {
 		}
 	}
 
-	appendAt(src, tmp, index)
-	appendAt(tmp, clean, 0)
+	appendAt(src, tmp[:], index)
 }
 
 func substitutionBox(src []byte, index int) {
+	var tmp [8]byte
+
 	for i := 0; i < 4; i++ {
 		tmp[i] = substitutionBoxTable[i][src[i*2+0+index]]&0xf0 |
 			substitutionBoxTable[i][src[i*2+1+index]]&0x0f
 	}
 
-	appendAt(src, tmp, index)
-	appendAt(tmp, clean, 0)
+	appendAt(src, tmp[:], index)
 }
 
 func expansion(src []byte, index int) {
+	var tmp [8]byte
+
 	tmp[0] = ((src[index+7] << 5) | (src[index+4] >> 3)) & 0x3f // ..0 vutsr
 	tmp[1] = ((src[index+4] << 1) | (src[index+5] >> 7)) & 0x3f // ..srqpo n
 	tmp[2] = ((src[index+4] << 5) | (src[index+5] >> 3)) & 0x3f // ..o nmlkj
@@ -206,28 +219,32 @@ func expansion(src []byte, index int) {
 	tmp[6] = ((src[index+6] << 5) | (src[index+7] >> 3)) & 0x3f // ..8 76543
 	tmp[7] = ((src[index+7] << 1) | (src[index+4] >> 7)) & 0x3f // ..43210 v
 
-	appendAt(src, tmp, index)
-	appendAt(tmp, clean, 0)
+	appendAt(src, tmp[:], index)
 }
-func shuffleDec(src []byte, index int) {
-	var shuffleDecTable = func() [256]byte {
-		var (
-			i, count int
-			out      = [256]byte{}
-			list     = []byte{0x00, 0x2b, 0x6c, 0x80, 0x01, 0x68, 0x48, 0x77, 0x60, 0xff, 0xb9, 0xc0, 0xfe, 0xeb}
-		)
-
-		for i := 0; i < 256; i++ {
-			out[i] = byte(i)
-		}
 
-		for i, count = 0, len(list); i < count; i += 2 {
-			out[list[i+0]] = list[i+1]
-			out[list[i+1]] = list[i+0]
-		}
+// shuffleDecTable swaps the byte pairs of its list, and maps every other
+// byte to itself.
+var shuffleDecTable = func() [256]byte {
+	var (
+		i, count int
+		out      = [256]byte{}
+		list     = []byte{0x00, 0x2b, 0x6c, 0x80, 0x01, 0x68, 0x48, 0x77, 0x60, 0xff, 0xb9, 0xc0, 0xfe, 0xeb}
+	)
+
+	for i := 0; i < 256; i++ {
+		out[i] = byte(i)
+	}
+
+	for i, count = 0, len(list); i < count; i += 2 {
+		out[list[i+0]] = list[i+1]
+		out[list[i+1]] = list[i+0]
+	}
 
-		return out
-	}()
+	return out
+}()
+
+func shuffleDec(src []byte, index int) {
+	var tmp [8]byte
 
 	tmp[0] = src[index+3]
 	tmp[1] = src[index+4]
@@ -237,8 +254,8 @@ func shuffleDec(src []byte, index int) {
 	tmp[5] = src[index+2]
 	tmp[6] = src[index+5]
 	tmp[7] = shuffleDecTable[src[index+7]]
-	appendAt(src, tmp, index)
-	appendAt(tmp, clean, 0)
+
+	appendAt(src, tmp[:], index)
 }
 
 func appendAt(slice []byte, elems []byte, index int) {
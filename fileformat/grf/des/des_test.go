@@ -0,0 +1,173 @@
+package des_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/grf/des"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dataPath = "./../../../data"
+
+	// fileHeaderLength is the size of the GRF header preceding entry data.
+	fileHeaderLength = 46
+)
+
+// blocks returns count distinct 8 byte blocks.
+func blocks(count int) []byte {
+	src := make([]byte, count*8)
+	for i := range src {
+		src[i] = byte(i*31 + 7)
+	}
+
+	return src
+}
+
+// decrypted returns the decryption of a single block.
+func decrypted(block []byte) []byte {
+	out := append([]byte(nil), block...)
+	des.DecodeBlocks(out)
+
+	return out
+}
+
+func TestDecodeBlocks(t *testing.T) {
+	src := blocks(3)
+	out := append([]byte(nil), src...)
+
+	des.DecodeBlocks(out)
+	assert.NotEqual(t, src, out)
+
+	// The cipher is a single Feistel round, so it is its own inverse.
+	des.DecodeBlocks(out)
+	assert.Equal(t, src, out)
+}
+
+func TestDecodeHeader(t *testing.T) {
+	src := blocks(24)
+	out := append([]byte(nil), src...)
+
+	des.DecodeHeader(out)
+
+	for i := 0; i < 24; i++ {
+		block := src[i*8 : i*8+8]
+		if i < 20 {
+			assert.Equal(t, decrypted(block), out[i*8:i*8+8], "block %d", i)
+		} else {
+			assert.Equal(t, block, out[i*8:i*8+8], "block %d", i)
+		}
+	}
+}
+
+func TestDecodeFull(t *testing.T) {
+	src := blocks(40)
+	out := append([]byte(nil), src...)
+
+	// A 4 digit entry length decrypts one block every 5 after the first 20,
+	// and shuffles every seventh block in between.
+	des.DecodeFull(out, len(out), 1000)
+
+	for i := 0; i < 40; i++ {
+		var (
+			block = src[i*8 : i*8+8]
+			got   = out[i*8 : i*8+8]
+		)
+
+		switch {
+		case i < 20 || i%5 == 0:
+			assert.Equal(t, decrypted(block), got, "block %d", i)
+		case i == 29 || i == 38:
+			assert.Equal(t, []byte{block[3], block[4], block[6], block[0], block[1], block[2], block[5], block[7]}, got, "block %d", i)
+		default:
+			assert.Equal(t, block, got, "block %d", i)
+		}
+	}
+}
+
+func TestDecodeFullShuffleSubstitution(t *testing.T) {
+	var tests = []struct {
+		In, Out byte
+	}{
+		{In: 0x00, Out: 0x2b},
+		{In: 0x2b, Out: 0x00},
+		{In: 0x6c, Out: 0x80},
+		{In: 0xff, Out: 0x60},
+		{In: 0x42, Out: 0x42},
+	}
+
+	for _, tt := range tests {
+		out := blocks(30)
+		out[27*8+7] = tt.In
+
+		// With a 5 digit entry length the cycle is 14, so blocks 20 to 27
+		// are not decrypted, and the eighth of them is shuffled.
+		des.DecodeFull(out, len(out), 10000)
+
+		assert.Equal(t, tt.Out, out[27*8+7])
+	}
+}
+
+// TestKnownAnswer decrypts entries of with-files.grf and checks them against
+// their known plaintext.
+func TestKnownAnswer(t *testing.T) {
+	archive, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"))
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		Name                  string
+		Offset                int
+		CompressedSize        int
+		CompressedSizeAligned int
+		HeaderOnly            bool
+		Expected              string
+	}{
+		{
+			Name:                  "compressed-des-header",
+			Offset:                90,
+			CompressedSize:        16,
+			CompressedSizeAligned: 16,
+			HeaderOnly:            true,
+			Expected:              strings.TrimSpace(strings.Repeat("test ", 15)),
+		},
+		{
+			Name:                  "compressed-des-full",
+			Offset:                106,
+			CompressedSize:        16,
+			CompressedSizeAligned: 16,
+			Expected:              strings.TrimSpace(strings.Repeat("test ", 15)),
+		},
+		{
+			Name:                  "big-compressed-des-full",
+			Offset:                122,
+			CompressedSize:        361,
+			CompressedSizeAligned: 368,
+			Expected:              "Lorem ipsum dolor sit amet, consectetur adipiscing elit. Sed venenatis bibendum venenatis. Aliquam quis velit urna. Suspendisse nec posuere sem. Donec risus quam, vulputate sed augue ultricies, dignissim hendrerit purus. Nulla euismod dolor enim, vel fermentum ex ultricies ac. Donec aliquet vehicula egestas. Sed accumsan velit ac mauris porta, id imperdiet purus aliquam. Phasellus et faucibus erat. Vestibulum ante ipsum primis in faucibus orci luctus et ultrices posuere cubilia curae; Pellentesque vel nisl efficitur, euismod augue eu, consequat dui. Maecenas vestibulum tortor purus, egestas posuere tortor imperdiet eget. Nulla sit amet placerat diam.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			start := fileHeaderLength + tt.Offset
+			data := append([]byte(nil), archive[start:start+tt.CompressedSizeAligned]...)
+
+			if tt.HeaderOnly {
+				des.DecodeHeader(data)
+			} else {
+				des.DecodeFull(data, tt.CompressedSizeAligned, tt.CompressedSize)
+			}
+
+			r, err := zlib.NewReader(bytes.NewReader(data[:tt.CompressedSize]))
+			assert.NoError(t, err)
+
+			plaintext, err := ioutil.ReadAll(r)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, string(plaintext))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package grf_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/grf"
+)
+
+func BenchmarkNewFile(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		f, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "custom.grf"))
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = f.Close()
+	}
+}
+
+func BenchmarkNewFileWithIndexCache(b *testing.B) {
+	opts := grf.OpenOptions{IndexCache: filepath.Join(b.TempDir(), "custom.idx")}
+
+	for i := 0; i < b.N; i++ {
+		f, err := grf.NewFileWithOptions(fmt.Sprintf("%s/%s", dataPath, "custom.grf"), opts)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = f.Close()
+	}
+}
+
+func BenchmarkGetEntry(b *testing.B) {
+	var benchmarks = []struct {
+		Name      string
+		FilePath  string
+		EntryName string
+	}{
+		{
+			Name:      "compressed",
+			FilePath:  fmt.Sprintf("%s/%s", dataPath, "custom.grf"),
+			EntryName: "data\\idnum2itemdesctable.txt",
+		},
+		{
+			Name:      "compressed with full encryption",
+			FilePath:  fmt.Sprintf("%s/%s", dataPath, "with-files.grf"),
+			EntryName: "big-compressed-des-full",
+		},
+	}
+
+	for _, bb := range benchmarks {
+		for _, mmap := range []bool{false, true} {
+			f, err := grf.NewFileWithOptions(bb.FilePath, grf.OpenOptions{Mmap: mmap})
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			name := bb.Name
+			if mmap {
+				name += " mmap"
+			}
+
+			b.Run(name, func(b *testing.B) {
+				b.SetBytes(int64(f.GetEntries()[bb.EntryName].Header.UncompressedSize))
+				b.ReportAllocs()
+
+				for i := 0; i < b.N; i++ {
+					if _, err := f.GetEntry(bb.EntryName); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			_ = f.Close()
+		}
+	}
+}
@@ -12,11 +12,31 @@ type entryFlags byte
 const (
 	entryHeaderLength = 4 + 4 + 4 + 1 + 4
 
+	// maxCompressionRatio is the highest ratio deflate can reach, used to
+	// bound how much a declared uncompressed size may preallocate.
+	maxCompressionRatio = 1032
+
 	typeFile          entryFlags = 0x01
 	typeEncryptMixed             = 0x02
 	typeEncryptHeader            = 0x04
 )
 
+// Encryption is the way the data of an entry is DES encrypted.
+type Encryption int
+
+const (
+	// EncryptionNone entries are stored as is.
+	EncryptionNone Encryption = iota
+
+	// EncryptionHeader entries have their first 20 blocks encrypted.
+	EncryptionHeader
+
+	// EncryptionFull entries have their first 20 blocks encrypted, then one
+	// block every cycle, which depends on the compressed size. Every seventh
+	// block in between is shuffled.
+	EncryptionFull
+)
+
 // EntryHeader ...
 type EntryHeader struct {
 	CompressedSize        uint32
@@ -34,23 +54,47 @@ type Entry struct {
 
 // Decode ...
 func (e *Entry) Decode(data []byte) error {
-	if e.Header.Flags&typeEncryptMixed != 0 {
-		des.DecodeFull(data, int(e.Header.CompressedSizeAligned), int(e.Header.CompressedSize))
-	} else if e.Header.Flags&typeEncryptHeader != 0 {
+	return e.Header.decode(data, e.Data)
+}
+
+// Encryption returns the encryption flagged in the header. Full encryption
+// wins when both flags are set.
+func (h EntryHeader) Encryption() Encryption {
+	switch {
+	case h.Flags&typeEncryptMixed != 0:
+		return EncryptionFull
+	case h.Flags&typeEncryptHeader != 0:
+		return EncryptionHeader
+	default:
+		return EncryptionNone
+	}
+}
+
+// decode decrypts data in place and writes the uncompressed entry to out.
+func (h EntryHeader) decode(data []byte, out *bytes.Buffer) error {
+	switch h.Encryption() {
+	case EncryptionFull:
+		des.DecodeFull(data, int(h.CompressedSizeAligned), int(h.CompressedSize))
+	case EncryptionHeader:
 		des.DecodeHeader(data)
 	}
 
-	if e.Header.CompressedSize == e.Header.UncompressedSize {
-		e.Data.Write(data)
+	// Stored entries are padded to the DES block size like the others.
+	if h.CompressedSize == h.UncompressedSize {
+		if int64(h.UncompressedSize) > int64(len(data)) {
+			return errors.New("entry data is truncated")
+		}
+
+		out.Write(data[:h.UncompressedSize])
 		return nil
 	}
 
-	data, err := decompress(data)
-	if err != nil {
+	if size := int64(h.UncompressedSize); size <= int64(len(data))*maxCompressionRatio {
+		out.Grow(int(size))
+	}
+	if err := decompress(out, data); err != nil {
 		return errors.Wrap(err, "could not decompress entry data")
 	}
 
-	e.Data.Write(data)
-
 	return nil
 }
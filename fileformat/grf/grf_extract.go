@@ -0,0 +1,120 @@
+package grf
+
+import (
+	"bytes"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ExtractOptions controls which entries Extract decodes and how.
+type ExtractOptions struct {
+	// Pattern selects entries with path.Match syntax, matched against the
+	// lower-cased entry name with slashes, such as "data/sprite/*/*.spr".
+	// Empty selects every entry.
+	Pattern string
+
+	// Workers is the number of entries decoded concurrently. Zero uses one
+	// worker per CPU.
+	Workers int
+
+	// Progress, when set, is called after every entry with the number of
+	// entries processed so far and the number selected. Calls are
+	// serialized.
+	Progress func(done, total int)
+}
+
+// Extract decodes the entries selected by opts across a pool of workers and
+// passes each of them to fn. fn is called concurrently, and data is only
+// valid until it returns. Extraction stops at the first error, which is
+// returned.
+func (f *File) Extract(opts ExtractOptions, fn func(name string, data []byte) error) error {
+	names, err := f.match(opts.Pattern)
+	if err != nil {
+		return err
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	var (
+		jobs = make(chan string)
+		stop = make(chan struct{})
+		wg   sync.WaitGroup
+
+		mu       sync.Mutex
+		done     int
+		firstErr error
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			out := new(bytes.Buffer)
+			for name := range jobs {
+				out.Reset()
+
+				err := f.read(f.entries[name].Header, out)
+				if err == nil {
+					err = fn(name, out.Bytes())
+				}
+
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = errors.Wrapf(err, "could not extract '%s'", name)
+					close(stop)
+				}
+				done++
+				if opts.Progress != nil {
+					opts.Progress(done, len(names))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, name := range names {
+		select {
+		case jobs <- name:
+		case <-stop:
+			break feed
+		}
+	}
+
+	close(jobs)
+	wg.Wait()
+
+	return firstErr
+}
+
+// match returns the sorted names of the entries selected by pattern.
+func (f *File) match(pattern string) ([]string, error) {
+	pattern = strings.ToLower(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, errors.Wrapf(err, "invalid pattern '%s'", pattern)
+	}
+
+	names := make([]string, 0, len(f.entries))
+	for name := range f.entries {
+		if pattern != "" {
+			if ok, _ := path.Match(pattern, strings.ToLower(strings.ReplaceAll(name, "\\", "/"))); !ok {
+				continue
+			}
+		}
+
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
@@ -5,7 +5,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
-	"log"
+	"io/fs"
 	"os"
 
 	"github.com/pkg/errors"
@@ -16,6 +16,11 @@ const (
 	fileHeaderSignature = "Master of Magic"
 )
 
+var (
+	errMmapUnsupported = errors.New("mmap is not supported on this platform")
+	errMmapTooLarge    = errors.New("file is too large to map on this platform")
+)
+
 // File ...
 type File struct {
 	Header struct {
@@ -29,33 +34,89 @@ type File struct {
 
 	entries map[string]*Entry
 	file    *os.File
+
+	// mapped is the whole archive when opened with OpenOptions.Mmap.
+	mapped []byte
+}
+
+// OpenOptions controls how a GRF file is opened.
+type OpenOptions struct {
+	// IndexCache is the path of a file caching the parsed file table. When
+	// it matches the archive's size, modification time and file table hash,
+	// the table is loaded from it instead of being parsed. Otherwise it is
+	// rewritten after parsing. Empty disables caching.
+	IndexCache string
+
+	// Mmap maps the archive into memory, so entry reads come from the OS
+	// page cache instead of a read into the heap. It is ignored on platforms
+	// without mmap support, and for archives too large to map.
+	Mmap bool
 }
 
 // NewFile loads a GRF file.
 func NewFile(path string) (*File, error) {
+	return NewFileWithOptions(path, OpenOptions{})
+}
+
+// NewFileWithOptions loads a GRF file, applying the given options.
+func NewFileWithOptions(path string, opts OpenOptions) (*File, error) {
 	f, err := os.Open(path)
 	if err != nil {
-		log.Fatal("error while opening file: ", err)
+		return nil, errors.Wrap(err, "could not open grf file")
 	}
 
 	fi, err := f.Stat()
 	if err != nil {
+		_ = f.Close()
 		return nil, err
 	}
 
 	grfFile := &File{file: f}
+	if err = grfFile.load(f, fi, opts); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
 
-	err = grfFile.parseHeader(f, fi)
+	// The archive is mapped last, so a failed open has nothing to unmap.
+	if opts.Mmap {
+		if grfFile.mapped, err = mmap(f, fi.Size()); err != nil && err != errMmapUnsupported && err != errMmapTooLarge {
+			_ = f.Close()
+			return nil, errors.Wrap(err, "could not map file")
+		}
+	}
+
+	return grfFile, nil
+}
+
+// load reads the header and the entries, from the index cache when it is
+// still valid.
+func (f *File) load(file *os.File, fi os.FileInfo, opts OpenOptions) error {
+	err := f.parseHeader(file, fi)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not read header")
+		return errors.Wrap(err, "could not read header")
 	}
 
-	err = grfFile.parseEntries(f)
+	table, err := f.readFileTable(file, fi)
 	if err != nil {
-		return nil, errors.Wrap(err, "could not read entries")
+		return errors.Wrap(err, "could not read entries")
 	}
 
-	return grfFile, nil
+	key := newIndexKey(fi, table)
+	if opts.IndexCache != "" && f.loadIndex(opts.IndexCache, key) {
+		return nil
+	}
+
+	err = f.parseEntries(table)
+	if err != nil {
+		return errors.Wrap(err, "could not read entries")
+	}
+
+	if opts.IndexCache != "" {
+		// The cache only saves time, so failing to write it is not an error.
+		_ = f.saveIndex(opts.IndexCache, key)
+	}
+
+	return nil
 }
 
 // GetEntries ...
@@ -70,22 +131,94 @@ func (f *File) GetEntry(name string) (entry *Entry, err error) {
 		return entry, fmt.Errorf("could not find entry '%s'", name)
 	}
 
-	_, err = f.file.Seek(int64(entry.Header.Offset)+fileHeaderLength, io.SeekStart)
-	if err != nil {
+	entry.Data.Reset()
+	if err = f.read(entry.Header, entry.Data); err != nil {
 		return nil, err
 	}
 
-	data := readNextBytes(f.file, int(entry.Header.CompressedSizeAligned))
-	if err = entry.Decode(data); err != nil {
+	return
+}
+
+// ReadFile returns a copy of the decoded contents of the named entry. Unlike
+// GetEntry, it does not touch the shared Entry.Data buffer and is safe for
+// concurrent use.
+func (f *File) ReadFile(name string) ([]byte, error) {
+	entry, exists := f.entries[name]
+	if !exists {
+		return nil, errors.Wrapf(fs.ErrNotExist, "could not find entry '%s'", name)
+	}
+
+	out := new(bytes.Buffer)
+	if err := f.read(entry.Header, out); err != nil {
 		return nil, err
 	}
 
-	return
+	return out.Bytes(), nil
+}
+
+// read writes the decoded contents of the entry with the given header to
+// out. It is safe for concurrent use.
+func (f *File) read(header EntryHeader, out *bytes.Buffer) error {
+	var (
+		offset = int64(header.Offset) + fileHeaderLength
+		size   = int64(header.CompressedSizeAligned)
+		data   []byte
+	)
+
+	// Mapped memory is read-only, and decryption happens in place.
+	if f.mapped != nil && header.Encryption() == EncryptionNone {
+		if offset+size > int64(len(f.mapped)) {
+			return errors.New("entry data out of bounds")
+		}
+
+		data = f.mapped[offset : offset+size]
+	} else {
+		buf := getBuffer()
+		defer putBuffer(buf)
+
+		buf.Grow(int(size))
+		data = buf.Bytes()[:size]
+
+		if err := f.readAt(data, offset); err != nil {
+			return errors.Wrap(err, "could not read entry data")
+		}
+	}
+
+	return header.decode(data, out)
 }
 
 // Close ...
 func (f *File) Close() error {
-	return f.file.Close()
+	var err error
+	if f.mapped != nil {
+		err = munmap(f.mapped)
+		f.mapped = nil
+	}
+
+	if closeErr := f.file.Close(); closeErr != nil {
+		if err != nil {
+			return errors.Wrapf(closeErr, "could not unmap file: %v", err)
+		}
+
+		return closeErr
+	}
+
+	return errors.Wrap(err, "could not unmap file")
+}
+
+// readAt fills data from the archive, starting at offset.
+func (f *File) readAt(data []byte, offset int64) error {
+	if f.mapped != nil {
+		if offset+int64(len(data)) > int64(len(f.mapped)) {
+			return io.ErrUnexpectedEOF
+		}
+
+		copy(data, f.mapped[offset:])
+		return nil
+	}
+
+	_, err := f.file.ReadAt(data, offset)
+	return err
 }
 
 func (f *File) parseHeader(file *os.File, fi os.FileInfo) error {
@@ -98,7 +231,7 @@ func (f *File) parseHeader(file *os.File, fi os.FileInfo) error {
 		return errors.New("invalid file signature")
 	}
 
-	if f.Header.Version != 0x200 {
+	if f.Header.Version != 0x200 && !f.isLegacy() {
 		return errors.New("unsupported file version")
 	}
 
@@ -114,19 +247,51 @@ func (f *File) parseHeader(file *os.File, fi os.FileInfo) error {
 	return nil
 }
 
-func (f *File) parseEntries(file *os.File) error {
-	_, _ = file.Seek(int64(f.Header.FileTableOffset), io.SeekStart)
+// readFileTable returns the file table, compressed unless the archive is a
+// legacy one.
+func (f *File) readFileTable(file *os.File, fi os.FileInfo) ([]byte, error) {
+	if _, err := file.Seek(int64(f.Header.FileTableOffset), io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	if f.isLegacy() {
+		table := make([]byte, fi.Size()-int64(f.Header.FileTableOffset))
+		if _, err := io.ReadFull(file, table); err != nil {
+			return nil, errors.Wrap(err, "could not read file table")
+		}
+
+		return table, nil
+	}
 
 	var compressedSize, uncompressedSize uint32
 
 	_ = binary.Read(file, binary.LittleEndian, &compressedSize)
-	_ = binary.Read(file, binary.LittleEndian, &uncompressedSize)
+	if err := binary.Read(file, binary.LittleEndian, &uncompressedSize); err != nil {
+		return nil, errors.Wrap(err, "could not read file table size")
+	}
 
-	data, err := decompress(readNextBytes(file, int(compressedSize)))
-	if err != nil {
+	table := make([]byte, compressedSize)
+	if _, err := io.ReadFull(file, table); err != nil {
+		return nil, errors.Wrap(err, "could not read file table")
+	}
+
+	return table, nil
+}
+
+func (f *File) parseEntries(table []byte) error {
+	if f.isLegacy() {
+		return f.parseLegacyEntries(table)
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+
+	if err := decompress(buf, table); err != nil {
 		return err
 	}
 
+	data := buf.Bytes()
+
 	for i, offset := 0, 0; i < int(f.Header.EntryCount); i++ {
 		var (
 			fileName    string
@@ -166,14 +331,3 @@ func (f *File) parseEntries(file *os.File) error {
 
 	return nil
 }
-
-func readNextBytes(reader io.Reader, number int) []byte {
-	bytesRead := make([]byte, number)
-
-	_, err := reader.Read(bytesRead)
-	if err != nil {
-		log.Fatal(errors.Wrap(err, "could not read next bytes"))
-	}
-
-	return bytesRead
-}
@@ -0,0 +1,90 @@
+package grf
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/gob"
+	"io/ioutil"
+	"os"
+)
+
+// indexCacheVersion must change whenever the layout of indexCache does, so
+// caches written by older versions are ignored.
+const indexCacheVersion = 1
+
+// indexKey identifies the archive an index cache was built from.
+type indexKey struct {
+	Size      int64
+	ModTime   int64
+	TableHash [sha1.Size]byte
+}
+
+func newIndexKey(fi os.FileInfo, table []byte) indexKey {
+	return indexKey{
+		Size:      fi.Size(),
+		ModTime:   fi.ModTime().UnixNano(),
+		TableHash: sha1.Sum(table),
+	}
+}
+
+// indexCache is the gob encoded content of an index cache file.
+type indexCache struct {
+	Version int
+	Key     indexKey
+	Names   []string
+	Headers []EntryHeader
+}
+
+// loadIndex fills the entries from the cache at path, and reports whether it
+// was usable for the archive identified by key.
+func (f *File) loadIndex(path string, key indexKey) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	var cache indexCache
+	if err = gob.NewDecoder(bufio.NewReader(file)).Decode(&cache); err != nil {
+		return false
+	}
+
+	if cache.Version != indexCacheVersion || cache.Key != key || len(cache.Names) != len(cache.Headers) {
+		return false
+	}
+
+	for i, name := range cache.Names {
+		f.entries[name] = &Entry{Header: cache.Headers[i], Data: new(bytes.Buffer)}
+	}
+
+	return true
+}
+
+// saveIndex writes the entries to the cache at path. The file is replaced
+// atomically, so a concurrent reader never sees a partial cache.
+func (f *File) saveIndex(path string, key indexKey) error {
+	cache := indexCache{
+		Version: indexCacheVersion,
+		Key:     key,
+		Names:   make([]string, 0, len(f.entries)),
+		Headers: make([]EntryHeader, 0, len(f.entries)),
+	}
+
+	for name, entry := range f.entries {
+		cache.Names = append(cache.Names, name)
+		cache.Headers = append(cache.Headers, entry.Header)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := gob.NewEncoder(buf).Encode(&cache); err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,107 @@
+package grf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/grf/des"
+)
+
+const (
+	// legacyEntryHeaderLength is the size of an entry header in 0x1xx
+	// archives, following the entry name.
+	legacyEntryHeaderLength = 4 + 4 + 4 + 1 + 4
+
+	// Sizes in 0x1xx entry headers are stored with these offsets added.
+	legacyCompressedSizeKey        = 0x2cb
+	legacyCompressedSizeAlignedKey = 0x92cb
+)
+
+// legacyHeaderEncrypted lists the extensions whose entries only have their
+// first blocks encrypted in 0x1xx archives. Entries of any other type are
+// fully encrypted.
+var legacyHeaderEncrypted = []string{".gnd", ".gat", ".act", ".str"}
+
+// isLegacy reports whether the archive uses the 0x102/0x103 layout, whose
+// file table is uncompressed and has encrypted names.
+func (f *File) isLegacy() bool {
+	return f.Header.Version == 0x102 || f.Header.Version == 0x103
+}
+
+// parseLegacyEntries reads a 0x1xx file table. Each entry is stored as
+//
+//	uint32 length, 2 unknown bytes, length-6 bytes of encrypted name,
+//	uint32 compressed size, uint32 aligned size, uint32 uncompressed size,
+//	uint8 flags, uint32 offset
+//
+// and the encryption flags are derived from the file extension.
+func (f *File) parseLegacyEntries(data []byte) error {
+	for i, offset := 0, 0; i < int(f.Header.EntryCount); i++ {
+		if offset+4 > len(data) {
+			return errors.New("file table is truncated")
+		}
+
+		length := int(binary.LittleEndian.Uint32(data[offset:]))
+		headerOffset := offset + 4 + length
+
+		if length < 6 || headerOffset < 0 || headerOffset+legacyEntryHeaderLength > len(data) {
+			return errors.Errorf("invalid entry name length %d", length)
+		}
+
+		header := data[headerOffset : headerOffset+legacyEntryHeaderLength]
+		flags := entryFlags(header[12])
+
+		if flags&typeFile != 0 {
+			name := decodeLegacyName(data[offset+6 : offset+length])
+			uncompressedSize := binary.LittleEndian.Uint32(header[8:])
+
+			f.entries[name] = &Entry{
+				Header: EntryHeader{
+					CompressedSize:        binary.LittleEndian.Uint32(header[0:]) - uncompressedSize - legacyCompressedSizeKey,
+					CompressedSizeAligned: binary.LittleEndian.Uint32(header[4:]) - legacyCompressedSizeAlignedKey,
+					UncompressedSize:      uncompressedSize,
+					Flags:                 flags | legacyEncryption(name),
+					Offset:                binary.LittleEndian.Uint32(header[13:]),
+				},
+				Data: new(bytes.Buffer),
+			}
+		}
+
+		offset = headerOffset + legacyEntryHeaderLength
+	}
+
+	return nil
+}
+
+// decodeLegacyName decrypts an entry name. Each block has its nibbles
+// swapped, then goes through DES.
+func decodeLegacyName(src []byte) string {
+	name := make([]byte, len(src))
+	for i, b := range src {
+		name[i] = b<<4 | b>>4
+	}
+
+	des.DecodeBlocks(name)
+
+	if i := bytes.IndexByte(name, 0); i >= 0 {
+		name = name[:i]
+	}
+
+	return string(name)
+}
+
+// legacyEncryption returns the encryption flag of a 0x1xx entry.
+func legacyEncryption(name string) entryFlags {
+	ext := strings.ToLower(path.Ext(strings.ReplaceAll(name, "\\", "/")))
+
+	for _, e := range legacyHeaderEncrypted {
+		if ext == e {
+			return typeEncryptHeader
+		}
+	}
+
+	return typeEncryptMixed
+}
@@ -2,10 +2,23 @@ package grf_test
 
 import (
 	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/project-midgard/midgarts/fileformat/grf/des"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -146,3 +159,384 @@ func TestEntryContents(t *testing.T) {
 		})
 	}
 }
+
+func TestIndexCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "with-files.grf")
+	cache := filepath.Join(dir, "with-files.idx")
+
+	data, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"))
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+	uncached, err := grf.NewFile(path)
+	assert.NoError(t, err)
+	defer uncached.Close()
+
+	f, err := grf.NewFileWithOptions(path, grf.OpenOptions{IndexCache: cache})
+	assert.NoError(t, err)
+	_ = f.Close()
+
+	written, err := ioutil.ReadFile(cache)
+	assert.NoError(t, err)
+
+	f, err = grf.NewFileWithOptions(path, grf.OpenOptions{IndexCache: cache})
+	assert.NoError(t, err)
+	assert.Equal(t, uncached.GetEntries(), f.GetEntries())
+
+	e, err := f.GetEntry("compressed-des-full")
+	assert.NoError(t, err)
+	assert.Equal(t, "test test test test test test test test test test test test test test test", e.Data.String())
+	_ = f.Close()
+
+	// A newer archive invalidates the cache, which is rewritten.
+	later := time.Now().Add(time.Hour)
+	assert.NoError(t, os.Chtimes(path, later, later))
+
+	f, err = grf.NewFileWithOptions(path, grf.OpenOptions{IndexCache: cache})
+	assert.NoError(t, err)
+	assert.Equal(t, uncached.GetEntries(), f.GetEntries())
+	_ = f.Close()
+
+	rewritten, err := ioutil.ReadFile(cache)
+	assert.NoError(t, err)
+	assert.NotEqual(t, written, rewritten)
+
+	// So does a cache that cannot be decoded.
+	assert.NoError(t, ioutil.WriteFile(cache, []byte("garbage"), 0644))
+
+	f, err = grf.NewFileWithOptions(path, grf.OpenOptions{IndexCache: cache})
+	assert.NoError(t, err)
+	assert.Equal(t, uncached.GetEntries(), f.GetEntries())
+	_ = f.Close()
+}
+
+func TestMmap(t *testing.T) {
+	path := fmt.Sprintf("%s/%s", dataPath, "with-files.grf")
+
+	f, err := grf.NewFile(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	mapped, err := grf.NewFileWithOptions(path, grf.OpenOptions{Mmap: true})
+	assert.NoError(t, err)
+
+	for name := range f.GetEntries() {
+		t.Run(name, func(t *testing.T) {
+			expected, expectedErr := f.GetEntry(name)
+			actual, err := mapped.GetEntry(name)
+
+			if expectedErr != nil {
+				assert.EqualError(t, err, expectedErr.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, expected.Data.String(), actual.Data.String())
+		})
+	}
+
+	assert.NoError(t, mapped.Close())
+}
+
+func TestExtract(t *testing.T) {
+	f, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "custom.grf"))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var (
+		mu       sync.Mutex
+		names    []string
+		progress []int
+	)
+
+	err = f.Extract(grf.ExtractOptions{
+		Pattern: "DATA/*.txt",
+		Workers: 4,
+		Progress: func(done, total int) {
+			assert.Equal(t, 4, total)
+			progress = append(progress, done)
+		},
+	}, func(name string, data []byte) error {
+		e, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "custom.grf"))
+		assert.NoError(t, err)
+		defer e.Close()
+
+		expected, err := e.GetEntry(name)
+		assert.NoError(t, err)
+		assert.Equal(t, expected.Data.Bytes(), data)
+
+		mu.Lock()
+		names = append(names, name)
+		mu.Unlock()
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"data\\11001.txt",
+		"data\\idnum2itemdesctable.txt",
+		"data\\idnum2itemdisplaynametable.txt",
+		"data\\resnametable.txt",
+	}, names)
+	assert.Equal(t, []int{1, 2, 3, 4}, progress)
+
+	err = f.Extract(grf.ExtractOptions{Pattern: "["}, nil)
+	assert.Error(t, err)
+}
+
+func TestExtractErrors(t *testing.T) {
+	f, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"))
+	assert.NoError(t, err)
+	defer f.Close()
+
+	// Concurrent DES decoding must not share state between workers.
+	var count int32
+	for i := 0; i < 20; i++ {
+		err = f.Extract(grf.ExtractOptions{Pattern: "*-des-*", Workers: 3}, func(name string, data []byte) error {
+			atomic.AddInt32(&count, 1)
+			return nil
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(60), count)
+
+	err = f.Extract(grf.ExtractOptions{}, func(name string, data []byte) error {
+		return nil
+	})
+	assert.EqualError(t, err, "could not extract 'corrupted': could not decompress entry data: zlib: invalid header")
+
+	err = f.Extract(grf.ExtractOptions{Pattern: "raw"}, func(name string, data []byte) error {
+		return fmt.Errorf("disk full")
+	})
+	assert.EqualError(t, err, "could not extract 'raw': disk full")
+}
+
+// writeLegacy writes a 0x1xx archive holding files, encrypting them the way
+// their extension requires.
+func writeLegacy(t *testing.T, name string, version uint32, files map[string]string) {
+	var (
+		data  = new(bytes.Buffer)
+		table = new(bytes.Buffer)
+	)
+
+	// Add a directory entry, which must be skipped.
+	entries := map[string]string{"data\\": ""}
+	for entryName, content := range files {
+		entries[entryName] = content
+	}
+
+	for entryName, content := range entries {
+		compressed := new(bytes.Buffer)
+		w := zlib.NewWriter(compressed)
+		_, _ = w.Write([]byte(content))
+		_ = w.Close()
+
+		size := compressed.Len()
+		aligned := make([]byte, (size+7)&^7)
+		copy(aligned, compressed.Bytes())
+
+		// Files are small enough that encryption and decryption are the same.
+		flags := byte(0x01)
+		switch path.Ext(strings.ReplaceAll(entryName, "\\", "/")) {
+		case ".gat":
+			des.DecodeHeader(aligned)
+		case "":
+			flags = 0x00
+		default:
+			des.DecodeFull(aligned, len(aligned), size)
+		}
+
+		encodedName := make([]byte, (len(entryName)+8)&^7)
+		copy(encodedName, entryName)
+		des.DecodeBlocks(encodedName)
+		for i, b := range encodedName {
+			encodedName[i] = b<<4 | b>>4
+		}
+
+		_ = binary.Write(table, binary.LittleEndian, uint32(len(encodedName)+6))
+		table.Write([]byte{0, 0})
+		table.Write(encodedName)
+		table.Write(make([]byte, 4))
+		_ = binary.Write(table, binary.LittleEndian, []uint32{
+			uint32(size + len(content) + 0x2cb),
+			uint32(len(aligned) + 0x92cb),
+			uint32(len(content)),
+		})
+		table.WriteByte(flags)
+		_ = binary.Write(table, binary.LittleEndian, uint32(data.Len()))
+
+		data.Write(aligned)
+	}
+
+	out := new(bytes.Buffer)
+	out.WriteString("Master of Magic")
+	out.Write(make([]byte, 15))
+	_ = binary.Write(out, binary.LittleEndian, []uint32{uint32(data.Len()), 0, uint32(len(entries) + 7), version})
+	out.Write(data.Bytes())
+	out.Write(table.Bytes())
+
+	assert.NoError(t, ioutil.WriteFile(name, out.Bytes(), 0644))
+}
+
+func TestLegacyVersions(t *testing.T) {
+	files := map[string]string{
+		"data\\readme.txt":                 "test test test test test test test test test test test test test test test",
+		"data\\prontera.gat":               "GRAT map data",
+		"data\\\xc0\xaf\xc0\xfa\\item.txt": "501#Red_Potion#",
+	}
+
+	for _, version := range []uint32{0x102, 0x103} {
+		t.Run(fmt.Sprintf("0x%x", version), func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "legacy.grf")
+			writeLegacy(t, name, version, files)
+
+			f, err := grf.NewFile(name)
+			assert.NoError(t, err)
+			defer f.Close()
+
+			assert.Len(t, f.GetEntries(), len(files))
+			assert.Equal(t, byte(0x05), byte(f.GetEntries()["data\\prontera.gat"].Header.Flags))
+			assert.Equal(t, byte(0x03), byte(f.GetEntries()["data\\readme.txt"].Header.Flags))
+
+			for entryName, content := range files {
+				e, err := f.GetEntry(entryName)
+				assert.NoError(t, err)
+				assert.Equal(t, content, e.Data.String())
+			}
+		})
+	}
+}
+
+func TestUnsupportedVersion(t *testing.T) {
+	f, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "incorrect-version.grf"))
+	assert.Nil(t, f)
+	assert.EqualError(t, err, "could not read header: unsupported file version")
+}
+
+func TestLegacyEmptyArchive(t *testing.T) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("%s/%s", dataPath, "incorrect-version.grf"))
+	assert.NoError(t, err)
+	binary.LittleEndian.PutUint32(data[42:], 0x103)
+
+	name := filepath.Join(t.TempDir(), "legacy.grf")
+	assert.NoError(t, ioutil.WriteFile(name, data, 0644))
+
+	f, err := grf.NewFile(name)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	assert.Empty(t, f.GetEntries())
+}
+
+func TestEntryEncryption(t *testing.T) {
+	var tests = []struct {
+		Name     string
+		Header   grf.EntryHeader
+		Expected grf.Encryption
+	}{
+		{Name: "none", Header: grf.EntryHeader{Flags: 0x01}, Expected: grf.EncryptionNone},
+		{Name: "full", Header: grf.EntryHeader{Flags: 0x03}, Expected: grf.EncryptionFull},
+		{Name: "header", Header: grf.EntryHeader{Flags: 0x05}, Expected: grf.EncryptionHeader},
+		{Name: "both flags", Header: grf.EntryHeader{Flags: 0x07}, Expected: grf.EncryptionFull},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Equal(t, tt.Expected, tt.Header.Encryption())
+		})
+	}
+}
+
+func TestDecodeStoredEntry(t *testing.T) {
+	const content = "stored entry"
+
+	var tests = []struct {
+		Name   string
+		Header grf.EntryHeader
+	}{
+		{Name: "not encrypted", Header: grf.EntryHeader{Flags: 0x01}},
+		{Name: "full encryption", Header: grf.EntryHeader{Flags: 0x03}},
+		{Name: "header encryption", Header: grf.EntryHeader{Flags: 0x05}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			data := make([]byte, 16)
+			copy(data, content)
+
+			// Below 20 blocks, both modes encrypt every block, and
+			// encrypting is the same as decrypting.
+			if tt.Header.Encryption() != grf.EncryptionNone {
+				des.DecodeBlocks(data)
+			}
+
+			e := &grf.Entry{Header: tt.Header, Data: new(bytes.Buffer)}
+			e.Header.CompressedSize = uint32(len(content))
+			e.Header.CompressedSizeAligned = uint32(len(data))
+			e.Header.UncompressedSize = uint32(len(content))
+
+			assert.NoError(t, e.Decode(data))
+			assert.Equal(t, content, e.Data.String())
+		})
+	}
+}
+
+func TestGetEntryTwice(t *testing.T) {
+	f, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"))
+	assert.NoError(t, err)
+
+	e, err := f.GetEntry("compressed")
+	assert.NoError(t, err)
+	expected := e.Data.String()
+
+	e, err = f.GetEntry("compressed")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, e.Data.String())
+}
+
+func TestReadFile(t *testing.T) {
+	path := fmt.Sprintf("%s/%s", dataPath, "with-files.grf")
+
+	f, err := grf.NewFileWithOptions(path, grf.OpenOptions{Mmap: true})
+	assert.NoError(t, err)
+	defer f.Close()
+
+	e, err := f.GetEntry("big-compressed-des-full")
+	assert.NoError(t, err)
+	expected := e.Data.String()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for _, name := range []string{"compressed", "big-compressed-des-full"} {
+				data, err := f.ReadFile(name)
+				assert.NoError(t, err)
+				if name == "big-compressed-des-full" {
+					assert.Equal(t, expected, string(data))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, err = f.ReadFile("missing")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestOpenMissingFile(t *testing.T) {
+	_, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "missing.grf"))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestCloseMapped(t *testing.T) {
+	f, err := grf.NewFileWithOptions(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"), grf.OpenOptions{Mmap: true})
+	assert.NoError(t, err)
+
+	assert.NoError(t, f.Close())
+	assert.True(t, errors.Is(f.Close(), os.ErrClosed))
+}
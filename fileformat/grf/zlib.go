@@ -4,20 +4,51 @@ import (
 	"bytes"
 	"compress/zlib"
 	"io"
+	"sync"
 )
 
-func decompress(data []byte) ([]byte, error) {
-	out := new(bytes.Buffer)
+var (
+	bufferPool = sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
 
-	zlibReader, err := zlib.NewReader(bytes.NewReader(data))
-	if err != nil {
-		return nil, err
+	zlibReaderPool sync.Pool
+)
+
+func getBuffer() *bytes.Buffer {
+	buf := bufferPool.Get().(*bytes.Buffer)
+	buf.Reset()
+
+	return buf
+}
+
+func putBuffer(buf *bytes.Buffer) {
+	bufferPool.Put(buf)
+}
+
+// decompress inflates data into out, reusing zlib readers across calls.
+func decompress(out *bytes.Buffer, data []byte) error {
+	var (
+		zlibReader io.ReadCloser
+		err        error
+	)
+
+	if r, ok := zlibReaderPool.Get().(io.ReadCloser); ok {
+		zlibReader = r
+		err = r.(zlib.Resetter).Reset(bytes.NewReader(data), nil)
+	} else {
+		zlibReader, err = zlib.NewReader(bytes.NewReader(data))
 	}
 
-	_, err = io.Copy(out, zlibReader)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return out.Bytes(), nil
+	defer zlibReaderPool.Put(zlibReader)
+
+	_, err = io.Copy(out, zlibReader)
+
+	return err
 }
@@ -0,0 +1,182 @@
+package pal
+
+import (
+	"image/color"
+	"io"
+	"math"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ColorCount = 256
+	FileSize   = ColorCount * 4
+)
+
+// Palette is a 256 color table, as stored in .pal files and at the end of
+// .spr files.
+type Palette struct {
+	Colors [ColorCount]color.NRGBA
+
+	// reserved keeps the unused fourth byte of each entry for Save.
+	reserved [ColorCount]byte
+}
+
+// Load reads a palette file.
+func Load(buf io.Reader) (*Palette, error) {
+	var data [FileSize]byte
+	if _, err := io.ReadFull(buf, data[:]); err != nil {
+		return nil, errors.Wrap(err, "could not read palette")
+	}
+
+	p := new(Palette)
+	for i := range p.Colors {
+		p.Colors[i] = color.NRGBA{R: data[i*4], G: data[i*4+1], B: data[i*4+2], A: 0xff}
+		p.reserved[i] = data[i*4+3]
+	}
+
+	return p, nil
+}
+
+// Save writes the palette in the same format Load reads.
+func (p *Palette) Save(w io.Writer) error {
+	var data [FileSize]byte
+	for i, c := range p.Colors {
+		data[i*4], data[i*4+1], data[i*4+2], data[i*4+3] = c.R, c.G, c.B, p.reserved[i]
+	}
+
+	_, err := w.Write(data[:])
+
+	return errors.Wrap(err, "could not write palette")
+}
+
+// SetGradient fills the colors from start to end, inclusive, with a linear
+// gradient between from and to.
+func (p *Palette) SetGradient(start, end int, from, to color.NRGBA) {
+	start, end = clampRange(start, end)
+
+	for i := start; i <= end; i++ {
+		t := 0.0
+		if end > start {
+			t = float64(i-start) / float64(end-start)
+		}
+
+		p.Colors[i] = color.NRGBA{
+			R: lerp(from.R, to.R, t),
+			G: lerp(from.G, to.G, t),
+			B: lerp(from.B, to.B, t),
+			A: lerp(from.A, to.A, t),
+		}
+	}
+}
+
+// ShiftHue rotates the hue of the colors from start to end, inclusive, by
+// the given amount of degrees, keeping their saturation and lightness.
+func (p *Palette) ShiftHue(start, end int, degrees float64) {
+	start, end = clampRange(start, end)
+
+	for i := start; i <= end; i++ {
+		h, s, l := rgbToHSL(p.Colors[i])
+		h = math.Mod(h+degrees/360, 1)
+		if h < 0 {
+			h++
+		}
+
+		c := hslToRGB(h, s, l)
+		c.A = p.Colors[i].A
+		p.Colors[i] = c
+	}
+}
+
+func clampRange(start, end int) (int, int) {
+	if start < 0 {
+		start = 0
+	}
+
+	if end >= ColorCount {
+		end = ColorCount - 1
+	}
+
+	return start, end
+}
+
+func lerp(a, b byte, t float64) byte {
+	return byte(math.Round(float64(a) + (float64(b)-float64(a))*t))
+}
+
+func rgbToHSL(c color.NRGBA) (h, s, l float64) {
+	var (
+		r   = float64(c.R) / 0xff
+		g   = float64(c.G) / 0xff
+		b   = float64(c.B) / 0xff
+		max = math.Max(r, math.Max(g, b))
+		min = math.Min(r, math.Min(g, b))
+	)
+
+	l = (max + min) / 2
+	if max == min {
+		return 0, 0, l
+	}
+
+	d := max - min
+	if l > 0.5 {
+		s = d / (2 - max - min)
+	} else {
+		s = d / (max + min)
+	}
+
+	switch max {
+	case r:
+		h = (g - b) / d
+		if g < b {
+			h += 6
+		}
+	case g:
+		h = (b-r)/d + 2
+	default:
+		h = (r-g)/d + 4
+	}
+
+	return h / 6, s, l
+}
+
+func hslToRGB(h, s, l float64) color.NRGBA {
+	if s == 0 {
+		v := byte(math.Round(l * 0xff))
+		return color.NRGBA{R: v, G: v, B: v, A: 0xff}
+	}
+
+	q := l * (1 + s)
+	if l >= 0.5 {
+		q = l + s - l*s
+	}
+	p := 2*l - q
+
+	return color.NRGBA{
+		R: byte(math.Round(hueToChannel(p, q, h+1.0/3) * 0xff)),
+		G: byte(math.Round(hueToChannel(p, q, h) * 0xff)),
+		B: byte(math.Round(hueToChannel(p, q, h-1.0/3) * 0xff)),
+		A: 0xff,
+	}
+}
+
+func hueToChannel(p, q, t float64) float64 {
+	if t < 0 {
+		t++
+	}
+
+	if t > 1 {
+		t--
+	}
+
+	switch {
+	case t < 1.0/6:
+		return p + (q-p)*6*t
+	case t < 1.0/2:
+		return q
+	case t < 2.0/3:
+		return p + (q-p)*(2.0/3-t)*6
+	default:
+		return p
+	}
+}
@@ -0,0 +1,64 @@
+package pal_test
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/pal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadSave(t *testing.T) {
+	data := make([]byte, pal.FileSize)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+
+	p, err := pal.Load(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0, G: 7, B: 14, A: 0xff}, p.Colors[0])
+	assert.Equal(t, color.NRGBA{R: 28, G: 35, B: 42, A: 0xff}, p.Colors[1])
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, p.Save(out))
+	assert.Equal(t, data, out.Bytes())
+
+	_, err = pal.Load(bytes.NewReader(data[:10]))
+	assert.Error(t, err)
+}
+
+func TestSetGradient(t *testing.T) {
+	p := new(pal.Palette)
+	p.SetGradient(10, 14, color.NRGBA{A: 0xff}, color.NRGBA{R: 200, G: 100, A: 0xff})
+
+	assert.Equal(t, color.NRGBA{}, p.Colors[9])
+	assert.Equal(t, color.NRGBA{A: 0xff}, p.Colors[10])
+	assert.Equal(t, color.NRGBA{R: 100, G: 50, A: 0xff}, p.Colors[12])
+	assert.Equal(t, color.NRGBA{R: 200, G: 100, A: 0xff}, p.Colors[14])
+	assert.Equal(t, color.NRGBA{}, p.Colors[15])
+}
+
+func TestShiftHue(t *testing.T) {
+	var tests = []struct {
+		Name     string
+		Color    color.NRGBA
+		Degrees  float64
+		Expected color.NRGBA
+	}{
+		{Name: "red to green", Color: color.NRGBA{R: 0xff, A: 0xff}, Degrees: 120, Expected: color.NRGBA{G: 0xff, A: 0xff}},
+		{Name: "red to blue backwards", Color: color.NRGBA{R: 0xff, A: 0xff}, Degrees: -120, Expected: color.NRGBA{B: 0xff, A: 0xff}},
+		{Name: "dark orange to dark cyan", Color: color.NRGBA{R: 0x80, G: 0x40, A: 0xff}, Degrees: 180, Expected: color.NRGBA{G: 0x40, B: 0x80, A: 0xff}},
+		{Name: "gray is unchanged", Color: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}, Degrees: 90, Expected: color.NRGBA{R: 0x80, G: 0x80, B: 0x80, A: 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			p := new(pal.Palette)
+			p.Colors[1] = tt.Color
+			p.ShiftHue(1, 1, tt.Degrees)
+			assert.Equal(t, tt.Expected, p.Colors[1])
+			assert.Equal(t, color.NRGBA{}, p.Colors[0])
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package spr_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+)
+
+// benchmarkSprite builds a sprite with 64 frames of 100x100 pixels, where
+// every other row is a transparent run.
+func benchmarkSprite() []byte {
+	frames := make([]sprtest.Frame, 64)
+	for i := range frames {
+		data := make([]byte, 0, 100*51)
+		for y := 0; y < 100; y++ {
+			if y%2 == 0 {
+				data = append(data, 0x00, 100)
+				continue
+			}
+
+			for x := 0; x < 100; x++ {
+				data = append(data, byte(1+(x+y+i)%255))
+			}
+		}
+
+		frames[i] = sprtest.Frame{Width: 100, Height: 100, Data: data}
+	}
+
+	return sprtest.Encode(frames)
+}
+
+func BenchmarkLoad(b *testing.B) {
+	data := benchmarkSprite()
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := spr.Load(bytes.NewReader(data)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkImageAt(b *testing.B) {
+	file, err := spr.Load(bytes.NewReader(benchmarkSprite()))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := file.ImageAt(i % len(file.Frames)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
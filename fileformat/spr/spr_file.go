@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"strconv"
 
 	"github.com/pkg/errors"
@@ -21,11 +22,34 @@ const (
 	SpriteFileTypeRGBA
 )
 
+// ErrFileTooLarge is returned when the sizes declared by a file would
+// allocate more than LoadOptions.MaxAllocation.
+var ErrFileTooLarge = errors.New("file exceeds the allocation limit")
+
+// TrailingDataError is returned by strict loads when data follows the
+// palette.
+type TrailingDataError struct {
+	// Offset is where the trailing data starts, right after the palette.
+	Offset int64
+	Size   int64
+}
+
+func (e *TrailingDataError) Error() string {
+	return fmt.Sprintf("%d bytes of trailing data after palette", e.Size)
+}
+
 type SpriteFrame struct {
-	SpriteType FileType
-	Width      uintptr
-	Height     uintptr
-	Data       []byte
+	SpriteType FileType `json:"type"`
+	Width      uintptr  `json:"width"`
+	Height     uintptr  `json:"height"`
+	Data       []byte   `json:"data"`
+
+	// Set by Trim: the position of the frame inside its untrimmed bounds, and
+	// the size of those bounds.
+	OffsetX        uintptr `json:"offsetX,omitempty"`
+	OffsetY        uintptr `json:"offsetY,omitempty"`
+	OriginalWidth  uintptr `json:"originalWidth,omitempty"`
+	OriginalHeight uintptr `json:"originalHeight,omitempty"`
 }
 
 type SpriteFile struct {
@@ -42,25 +66,189 @@ type SpriteFile struct {
 	Palette *bytes.Buffer
 }
 
+// LoadOptions controls optional processing done while loading a sprite file.
+type LoadOptions struct {
+	// DeduplicateFrames makes frames with identical type, size and pixels
+	// share the same *SpriteFrame, so they can share atlas space too.
+	DeduplicateFrames bool
+
+	// TrimFrames removes fully transparent borders from every frame.
+	TrimFrames bool
+
+	// Strict rejects files with data left after the palette, which usually
+	// means the frame counts or sizes were not what the writer intended.
+	Strict bool
+
+	// MaxAllocation caps the bytes reserved for the frame table, frame
+	// pixels and palette, checked against the declared counts and sizes
+	// before allocating. Zero means no limit.
+	MaxAllocation int64
+}
+
+// allocationBudget tracks the bytes a parse may still allocate. A nil budget
+// is unlimited.
+type allocationBudget struct {
+	remaining int64
+}
+
+func newAllocationBudget(limit int64) *allocationBudget {
+	if limit <= 0 {
+		return nil
+	}
+
+	return &allocationBudget{remaining: limit}
+}
+
+func (b *allocationBudget) reserve(n int64) error {
+	if b == nil {
+		return nil
+	}
+
+	if n > b.remaining {
+		return ErrFileTooLarge
+	}
+
+	b.remaining -= n
+
+	return nil
+}
+
 func Load(buf io.Reader) (file *SpriteFile, err error) {
+	return LoadWithOptions(buf, LoadOptions{})
+}
+
+// LoadWithOptions loads a sprite file, applying the given options.
+func LoadWithOptions(buf io.Reader, opts LoadOptions) (file *SpriteFile, err error) {
 	file = new(SpriteFile)
+	budget := newAllocationBudget(opts.MaxAllocation)
+
+	counter := &countingReader{r: buf}
+	buf = counter
 
-	if err := file.parseHeader(buf); err != nil {
+	if err := file.parseHeader(buf, budget); err != nil {
 		return nil, err
 	}
 
 	if file.Header.Version >= 2.1 {
-		if err = file.readCompressedIndexedFrames(buf); err != nil {
+		if err = file.readCompressedIndexedFrames(buf, budget); err != nil {
 			return nil, err
 		}
 	} else {
 		return nil, fmt.Errorf("unsupported version %f\n", file.Header.Version)
 	}
 
+	if err = file.readRGBAFrames(buf, budget); err != nil {
+		return nil, err
+	}
+
+	if _, err = io.ReadFull(buf, file.Palette.Bytes()); err != nil {
+		return nil, errors.Wrap(err, "could not read palette")
+	}
+
+	if opts.Strict {
+		offset := counter.n
+		if n, _ := io.Copy(ioutil.Discard, buf); n > 0 {
+			return nil, &TrailingDataError{Offset: offset, Size: n}
+		}
+	}
+
+	if opts.TrimFrames {
+		for _, frame := range file.Frames {
+			frame.Trim()
+		}
+	}
+
+	if opts.DeduplicateFrames {
+		file.deduplicateFrames()
+	}
+
 	return file, nil
 }
 
-func (f *SpriteFile) parseHeader(buf io.Reader) error {
+// Save writes the sprite file as version 2.1, the version Load reads. Indexed
+// frames are RLE-encoded and must come before RGBA frames.
+func (f *SpriteFile) Save(w io.Writer) error {
+	indexedFrameCount, rgbaFrameCount, err := frameCounts(f.Frames)
+	if err != nil {
+		return err
+	}
+
+	palette := make([]byte, PaletteSize)
+	if f.Palette != nil {
+		if f.Palette.Len() != PaletteSize {
+			return fmt.Errorf("palette has %d bytes, expected %d", f.Palette.Len(), PaletteSize)
+		}
+
+		copy(palette, f.Palette.Bytes())
+	}
+
+	buf := new(bytes.Buffer)
+	buf.WriteString(HeaderSignature)
+	buf.Write([]byte{1, 2})
+	_ = binary.Write(buf, binary.LittleEndian, []uint16{indexedFrameCount, rgbaFrameCount})
+
+	for i, frame := range f.Frames {
+		header := []uint16{uint16(frame.Width), uint16(frame.Height)}
+
+		if frame.SpriteType == SpriteFileTypeRGBA {
+			_ = binary.Write(buf, binary.LittleEndian, header)
+			buf.Write(frame.Data)
+			continue
+		}
+
+		data := encodeRLE(frame.Data)
+		if len(data) > math.MaxUint16 {
+			return fmt.Errorf("frame %d encodes to %d bytes, expected at most %d", i, len(data), math.MaxUint16)
+		}
+
+		_ = binary.Write(buf, binary.LittleEndian, append(header, uint16(len(data))))
+		buf.Write(data)
+	}
+
+	buf.Write(palette)
+
+	if _, err = w.Write(buf.Bytes()); err != nil {
+		return errors.Wrap(err, "could not write sprite file")
+	}
+
+	return nil
+}
+
+// frameCounts validates frames for writing and returns the number of indexed
+// and RGBA frames.
+func frameCounts(frames []*SpriteFrame) (indexed, rgba uint16, err error) {
+	if len(frames) > math.MaxUint16 {
+		return 0, 0, fmt.Errorf("%d frames, expected at most %d", len(frames), math.MaxUint16)
+	}
+
+	for i, frame := range frames {
+		if frame == nil {
+			return 0, 0, fmt.Errorf("frame %d is empty", i)
+		}
+
+		if frame.Width > math.MaxUint16 || frame.Height > math.MaxUint16 {
+			return 0, 0, fmt.Errorf("frame %d is %dx%d, expected at most %d pixels wide and high", i, frame.Width, frame.Height, math.MaxUint16)
+		}
+
+		bytesPerPixel := 1
+		if frame.SpriteType == SpriteFileTypeRGBA {
+			bytesPerPixel = 4
+			rgba++
+		} else if rgba > 0 {
+			return 0, 0, errors.New("indexed frames must come before rgba frames")
+		} else {
+			indexed++
+		}
+
+		if size := int(frame.Width) * int(frame.Height) * bytesPerPixel; len(frame.Data) != size {
+			return 0, 0, fmt.Errorf("frame %d has %d bytes of data, expected %d", i, len(frame.Data), size)
+		}
+	}
+
+	return indexed, rgba, nil
+}
+
+func (f *SpriteFile) parseHeader(buf io.Reader, budget *allocationBudget) error {
 	var signature [2]byte
 	_ = binary.Read(buf, binary.LittleEndian, &signature)
 
@@ -89,6 +277,11 @@ func (f *SpriteFile) parseHeader(buf io.Reader) error {
 		}
 	}
 
+	frameCount := int64(indexedFrameCount) + int64(rgbaFrameCount)
+	if err = budget.reserve(frameCount*8 + PaletteSize); err != nil {
+		return err
+	}
+
 	f.Header.Signature = signatureStr
 	f.Header.Version = float32(version)
 	f.Header.IndexedFrameCount = indexedFrameCount
@@ -101,28 +294,142 @@ func (f *SpriteFile) parseHeader(buf io.Reader) error {
 }
 
 // Parse .spr indexed images encoded with run-length encoding (RLE)
-func (f *SpriteFile) readCompressedIndexedFrames(buf io.Reader) error {
+func (f *SpriteFile) readCompressedIndexedFrames(buf io.Reader, budget *allocationBudget) error {
+	scratch := getBuffer()
+	defer putBuffer(scratch)
+
 	for i := 0; i < int(f.Header.IndexedFrameCount); i++ {
-		var (
-			width, height uint16
-			data          []byte
-		)
+		var width, height, size uint16
 
 		_ = binary.Read(buf, binary.LittleEndian, &width)
 		_ = binary.Read(buf, binary.LittleEndian, &height)
 
-		data, err := ioutil.ReadAll(io.LimitReader(buf, int64(width*height)))
-		if err != nil {
+		if err := binary.Read(buf, binary.LittleEndian, &size); err != nil {
+			return errors.Wrap(err, "could not read indexed frame size")
+		}
+
+		if err := budget.reserve(int64(width) * int64(height)); err != nil {
+			return err
+		}
+
+		scratch.Reset()
+		if _, err := io.CopyN(scratch, buf, int64(size)); err != nil {
 			return errors.Wrap(err, "could not read indexed frames data")
 		}
 
+		data, err := decodeRLE(scratch.Bytes(), int(width)*int(height))
+		if err != nil {
+			return errors.Wrapf(err, "could not decode indexed frame %d", i)
+		}
+
 		f.Frames[i] = &SpriteFrame{
 			SpriteType: SpriteFileTypePAL,
 			Width:      uintptr(width),
-			Height:     uintptr(width),
+			Height:     uintptr(height),
 			Data:       data,
 		}
 	}
 
 	return nil
 }
+
+// Parse .spr true color images, stored as bottom-up ABGR pixels
+func (f *SpriteFile) readRGBAFrames(buf io.Reader, budget *allocationBudget) error {
+	for i := 0; i < int(f.Header.RGBAFrameCount); i++ {
+		var width, height uint16
+
+		_ = binary.Read(buf, binary.LittleEndian, &width)
+		_ = binary.Read(buf, binary.LittleEndian, &height)
+
+		if err := budget.reserve(int64(width) * int64(height) * 4); err != nil {
+			return err
+		}
+
+		data := make([]byte, int(width)*int(height)*4)
+		if _, err := io.ReadFull(buf, data); err != nil {
+			return errors.Wrap(err, "could not read rgba frames data")
+		}
+
+		f.Frames[int(f.Header.RGBAIndex)+i] = &SpriteFrame{
+			SpriteType: SpriteFileTypeRGBA,
+			Width:      uintptr(width),
+			Height:     uintptr(height),
+			Data:       data,
+		}
+	}
+
+	return nil
+}
+
+// decodeRLE expands runs of transparent pixels, encoded as a zero followed by
+// the run length.
+func decodeRLE(src []byte, size int) ([]byte, error) {
+	out := make([]byte, 0, size)
+
+	for i := 0; i < len(src); i++ {
+		var (
+			c     = src[i]
+			count = 1
+		)
+
+		if c == 0 {
+			if i++; i >= len(src) {
+				return nil, errors.New("truncated run")
+			}
+
+			if src[i] > 0 {
+				count = int(src[i])
+			}
+		}
+
+		if len(out)+count > size {
+			return nil, errors.New("run overflows frame")
+		}
+
+		for ; count > 0; count-- {
+			out = append(out, c)
+		}
+	}
+
+	if len(out) != size {
+		return nil, fmt.Errorf("decoded %d pixels, expected %d", len(out), size)
+	}
+
+	return out, nil
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+
+	return n, err
+}
+
+// encodeRLE is the inverse of decodeRLE. Runs longer than 255 pixels are
+// split.
+func encodeRLE(src []byte) []byte {
+	out := make([]byte, 0, len(src))
+
+	for i := 0; i < len(src); i++ {
+		if src[i] != 0 {
+			out = append(out, src[i])
+			continue
+		}
+
+		count := 1
+		for count < 0xff && i+1 < len(src) && src[i+1] == 0 {
+			count++
+			i++
+		}
+
+		out = append(out, 0, byte(count))
+	}
+
+	return out
+}
@@ -1,7 +1,287 @@
 package spr_test
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/pal"
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/stretchr/testify/assert"
+)
 
 func TestNewFile(t *testing.T) {
 
 }
+
+func TestLoad(t *testing.T) {
+	var tests = []struct {
+		Name           string
+		Frames         []sprtest.Frame
+		ExpectedFrames []*spr.SpriteFrame
+	}{
+		{
+			Name: "load indexed frames with transparent runs",
+			Frames: []sprtest.Frame{
+				{Width: 3, Height: 2, Data: []byte{0x00, 0x02, 0x05, 0x06, 0x00, 0x02}},
+				{Width: 2, Height: 1, Data: []byte{0x00, 0x00, 0x07}},
+			},
+			ExpectedFrames: []*spr.SpriteFrame{
+				{SpriteType: spr.SpriteFileTypePAL, Width: 3, Height: 2, Data: []byte{0, 0, 5, 6, 0, 0}},
+				{SpriteType: spr.SpriteFileTypePAL, Width: 2, Height: 1, Data: []byte{0, 7}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			file, err := spr.Load(bytes.NewReader(sprtest.Encode(tt.Frames)))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.ExpectedFrames, file.Frames)
+		})
+	}
+}
+
+func TestLoadWithDeduplicateFrames(t *testing.T) {
+	data := sprtest.Encode([]sprtest.Frame{
+		{Width: 2, Height: 1, Data: []byte{0x01, 0x02}},
+		{Width: 2, Height: 1, Data: []byte{0x01, 0x03}},
+		{Width: 2, Height: 1, Data: []byte{0x01, 0x02}},
+		{Width: 1, Height: 2, Data: []byte{0x01, 0x02}},
+	})
+
+	file, err := spr.LoadWithOptions(bytes.NewReader(data), spr.LoadOptions{DeduplicateFrames: true})
+	assert.NoError(t, err)
+	assert.Len(t, file.Frames, 4)
+	assert.Same(t, file.Frames[0], file.Frames[2])
+	assert.NotSame(t, file.Frames[0], file.Frames[1])
+	assert.NotSame(t, file.Frames[0], file.Frames[3])
+
+	file, err = spr.Load(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.NotSame(t, file.Frames[0], file.Frames[2])
+	assert.Equal(t, file.Frames[0], file.Frames[2])
+}
+
+func TestLoadWithStrict(t *testing.T) {
+	data := append(sprtest.Encode([]sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0x01}}}), 0x00, 0x00)
+
+	_, err := spr.Load(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	_, err = spr.LoadWithOptions(bytes.NewReader(data), spr.LoadOptions{Strict: true})
+	assert.EqualError(t, err, "2 bytes of trailing data after palette")
+
+	var trailing *spr.TrailingDataError
+	assert.True(t, errors.As(err, &trailing))
+	assert.Equal(t, int64(len(data)-2), trailing.Offset)
+}
+
+// transparentRun returns the RLE encoding of n transparent pixels.
+func transparentRun(n int) []byte {
+	var data []byte
+	for ; n > 0xff; n -= 0xff {
+		data = append(data, 0x00, 0xff)
+	}
+
+	return append(data, 0x00, byte(n))
+}
+
+func TestLoadWithMaxAllocation(t *testing.T) {
+	frames := []sprtest.Frame{
+		{Width: 100, Height: 100, Data: transparentRun(100 * 100)},
+		{Width: 200, Height: 100, Data: transparentRun(200 * 100)},
+	}
+
+	var tests = []struct {
+		Name          string
+		Data          []byte
+		MaxAllocation int64
+		ExpectedErr   error
+	}{
+		{Name: "no limit", Data: sprtest.Encode(frames)},
+		{Name: "within limit", Data: sprtest.Encode(frames), MaxAllocation: 2*8 + spr.PaletteSize + 100*100 + 200*100},
+		{Name: "frame table too large", Data: sprtest.Encode(frames), MaxAllocation: 16, ExpectedErr: spr.ErrFileTooLarge},
+		{Name: "indexed frame too large", Data: sprtest.Encode(frames), MaxAllocation: 20000, ExpectedErr: spr.ErrFileTooLarge},
+		{
+			Name:          "rgba frame too large",
+			Data:          sprtest.EncodeWithRGBA(nil, []sprtest.Frame{{Width: 0xffff, Height: 0xffff}}, nil),
+			MaxAllocation: 1 << 20,
+			ExpectedErr:   spr.ErrFileTooLarge,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := spr.LoadWithOptions(bytes.NewReader(tt.Data), spr.LoadOptions{MaxAllocation: tt.MaxAllocation})
+			assert.Equal(t, tt.ExpectedErr, err)
+		})
+	}
+}
+
+func TestImageAt(t *testing.T) {
+	palette := make([]byte, spr.PaletteSize)
+	copy(palette[4:], []byte{0xff, 0x00, 0x00, 0x00, 0x00, 0xff, 0x00, 0x00})
+
+	file, err := spr.Load(bytes.NewReader(sprtest.EncodeWithRGBA(
+		[]sprtest.Frame{{Width: 3, Height: 1, Data: []byte{0x01, 0x00, 0x01, 0x02}}},
+		[]sprtest.Frame{{Width: 1, Height: 2, Data: []byte{
+			0xff, 0xff, 0x00, 0x00, // bottom row, ABGR
+			0x80, 0x00, 0x00, 0xff, // top row, ABGR
+		}}},
+		palette,
+	)))
+	assert.NoError(t, err)
+	assert.Len(t, file.Frames, 2)
+
+	img, err := file.ImageAt(0)
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, img.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{}, img.NRGBAAt(1, 0))
+	assert.Equal(t, color.NRGBA{G: 0xff, A: 0xff}, img.NRGBAAt(2, 0))
+
+	img, err = file.ImageAt(1)
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0x80}, img.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{B: 0xff, A: 0xff}, img.NRGBAAt(0, 1))
+
+	_, err = file.ImageAt(2)
+	assert.Error(t, err)
+}
+
+func TestWithPalette(t *testing.T) {
+	file, err := spr.Load(bytes.NewReader(sprtest.Encode([]sprtest.Frame{{Width: 2, Height: 1, Data: []byte{0x01, 0x02}}})))
+	assert.NoError(t, err)
+
+	p := new(pal.Palette)
+	p.Colors[1] = color.NRGBA{R: 0xff, A: 0xff}
+	p.Colors[2] = color.NRGBA{B: 0xff, A: 0xff}
+
+	dyed := file.WithPalette(p)
+	assert.Same(t, file.Frames[0], dyed.Frames[0])
+
+	img, err := dyed.ImageAt(0)
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, img.NRGBAAt(0, 0))
+	assert.Equal(t, color.NRGBA{B: 0xff, A: 0xff}, img.NRGBAAt(1, 0))
+
+	img, err = file.ImageAt(0)
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{A: 0xff}, img.NRGBAAt(0, 0))
+}
+
+func TestConvertFrame(t *testing.T) {
+	file, err := spr.Load(bytes.NewReader(sprtest.EncodeWithRGBA(
+		nil,
+		[]sprtest.Frame{{Width: 2, Height: 1, Data: []byte{
+			0x80, 0x00, 0x00, 0xff,
+			0x00, 0xff, 0xff, 0xff,
+		}}},
+		make([]byte, spr.PaletteSize),
+	)))
+	assert.NoError(t, err)
+
+	img, err := file.ConvertFrame(0, spr.ConvertOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0x80}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{R: 0xff, G: 0xff, B: 0xff}, img.At(1, 0))
+
+	img, err = file.ConvertFrame(0, spr.ConvertOptions{PremultiplyAlpha: true})
+	assert.NoError(t, err)
+	assert.Equal(t, color.RGBA{R: 0x80, A: 0x80}, img.At(0, 0))
+	assert.Equal(t, color.RGBA{}, img.At(1, 0))
+
+	_, err = file.ConvertFrame(1, spr.ConvertOptions{PremultiplyAlpha: true})
+	assert.Error(t, err)
+}
+
+func TestConvertFrameWithColorKey(t *testing.T) {
+	palette := make([]byte, spr.PaletteSize)
+	copy(palette[4:], []byte{
+		0xff, 0x00, 0xff, 0x00, // magenta
+		0xfc, 0x04, 0xfc, 0x00, // near magenta
+		0xf0, 0x00, 0xf0, 0x00, // pink
+	})
+
+	file, err := spr.Load(bytes.NewReader(sprtest.EncodeWithRGBA(
+		[]sprtest.Frame{{Width: 3, Height: 1, Data: []byte{0x01, 0x02, 0x03}}},
+		nil,
+		palette,
+	)))
+	assert.NoError(t, err)
+
+	img, err := file.ConvertFrame(0, spr.ConvertOptions{
+		ColorKey:          &color.NRGBA{R: 0xff, B: 0xff, A: 0xff},
+		ColorKeyTolerance: 4,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{}, img.At(0, 0))
+	assert.Equal(t, color.NRGBA{}, img.At(1, 0))
+	assert.Equal(t, color.NRGBA{R: 0xf0, B: 0xf0, A: 0xff}, img.At(2, 0))
+
+	img, err = file.ConvertFrame(0, spr.ConvertOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0xff, B: 0xff, A: 0xff}, img.At(0, 0))
+}
+
+func TestLoadErrors(t *testing.T) {
+	var tests = []struct {
+		Name   string
+		Frames []sprtest.Frame
+	}{
+		{Name: "run overflows frame", Frames: []sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0x00, 0x02}}}},
+		{Name: "truncated run", Frames: []sprtest.Frame{{Width: 2, Height: 1, Data: []byte{0x01, 0x00}}}},
+		{Name: "missing pixels", Frames: []sprtest.Frame{{Width: 2, Height: 2, Data: []byte{0x01}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := spr.Load(bytes.NewReader(sprtest.Encode(tt.Frames)))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSave(t *testing.T) {
+	palette := make([]byte, spr.PaletteSize)
+	copy(palette[4:], []byte{0xff, 0x00, 0x00, 0x01, 0x00, 0xff, 0x00, 0x00})
+
+	data := sprtest.EncodeWithRGBA(
+		[]sprtest.Frame{
+			{Width: 3, Height: 2, Data: []byte{0x00, 0x02, 0x05, 0x06, 0x00, 0x02}},
+			{Width: 300, Height: 1, Data: []byte{0x00, 0xff, 0x00, 0x2c, 0x01}},
+		},
+		[]sprtest.Frame{{Width: 1, Height: 2, Data: []byte{0xff, 0xff, 0x00, 0x00, 0x80, 0x00, 0x00, 0xff}}},
+		palette,
+	)
+
+	file, err := spr.Load(bytes.NewReader(data))
+	assert.NoError(t, err)
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, file.Save(buf))
+	assert.Equal(t, data, buf.Bytes())
+}
+
+func TestSaveErrors(t *testing.T) {
+	var tests = []struct {
+		Name   string
+		Frames []*spr.SpriteFrame
+	}{
+		{Name: "frame too wide", Frames: []*spr.SpriteFrame{{Width: 1 << 16, Height: 1, Data: make([]byte, 1<<16)}}},
+		{Name: "data size mismatch", Frames: []*spr.SpriteFrame{{Width: 2, Height: 1, Data: []byte{0x01}}}},
+		{Name: "indexed after rgba", Frames: []*spr.SpriteFrame{
+			{SpriteType: spr.SpriteFileTypeRGBA, Width: 1, Height: 1, Data: make([]byte, 4)},
+			{SpriteType: spr.SpriteFileTypePAL, Width: 1, Height: 1, Data: make([]byte, 1)},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			file := &spr.SpriteFile{Frames: tt.Frames}
+			assert.Error(t, file.Save(new(bytes.Buffer)))
+		})
+	}
+}
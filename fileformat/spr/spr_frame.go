@@ -0,0 +1,126 @@
+package spr
+
+import (
+	"bytes"
+	"hash/fnv"
+)
+
+// hash returns a digest of the frame type, size and pixels.
+func (f *SpriteFrame) hash() uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte{
+		byte(f.SpriteType),
+		byte(f.Width), byte(f.Width >> 8),
+		byte(f.Height), byte(f.Height >> 8),
+		byte(f.OffsetX), byte(f.OffsetX >> 8),
+		byte(f.OffsetY), byte(f.OffsetY >> 8),
+	})
+	_, _ = h.Write(f.Data)
+
+	return h.Sum64()
+}
+
+func (f *SpriteFrame) equal(other *SpriteFrame) bool {
+	return f.SpriteType == other.SpriteType &&
+		f.Width == other.Width &&
+		f.Height == other.Height &&
+		f.OffsetX == other.OffsetX &&
+		f.OffsetY == other.OffsetY &&
+		f.OriginalWidth == other.OriginalWidth &&
+		f.OriginalHeight == other.OriginalHeight &&
+		bytes.Equal(f.Data, other.Data)
+}
+
+// Trim crops fully transparent rows and columns from the frame borders and
+// records where the remaining pixels were, so the frame can still be drawn
+// around its original center.
+func (f *SpriteFrame) Trim() {
+	var (
+		width         = int(f.Width)
+		height        = int(f.Height)
+		bytesPerPixel = 1
+	)
+
+	if f.SpriteType == SpriteFileTypeRGBA {
+		bytesPerPixel = 4
+	}
+
+	if f.OriginalWidth == 0 && f.OriginalHeight == 0 {
+		f.OriginalWidth, f.OriginalHeight = f.Width, f.Height
+	}
+
+	// bounds of the opaque pixels, in data row order
+	minX, minRow, maxX, maxRow := width, height, -1, -1
+	for row := 0; row < height; row++ {
+		for x := 0; x < width; x++ {
+			// index 0 of indexed frames and alpha, the first byte of ABGR pixels
+			if f.Data[(row*width+x)*bytesPerPixel] == 0 {
+				continue
+			}
+
+			if x < minX {
+				minX = x
+			}
+			if x > maxX {
+				maxX = x
+			}
+			if row < minRow {
+				minRow = row
+			}
+			if row > maxRow {
+				maxRow = row
+			}
+		}
+	}
+
+	if maxX < 0 {
+		f.Width, f.Height, f.Data = 0, 0, []byte{}
+		return
+	}
+
+	trimmedWidth, trimmedHeight := maxX-minX+1, maxRow-minRow+1
+	if trimmedWidth == width && trimmedHeight == height {
+		return
+	}
+
+	data := make([]byte, 0, trimmedWidth*trimmedHeight*bytesPerPixel)
+	for row := minRow; row <= maxRow; row++ {
+		start := (row*width + minX) * bytesPerPixel
+		data = append(data, f.Data[start:start+trimmedWidth*bytesPerPixel]...)
+	}
+
+	// rgba frames are stored bottom-up, offsets are top-down
+	top := minRow
+	if f.SpriteType == SpriteFileTypeRGBA {
+		top = height - maxRow - 1
+	}
+
+	f.OffsetX += uintptr(minX)
+	f.OffsetY += uintptr(top)
+	f.Width, f.Height, f.Data = uintptr(trimmedWidth), uintptr(trimmedHeight), data
+}
+
+// deduplicateFrames points every duplicated frame to its first occurrence.
+func (f *SpriteFile) deduplicateFrames() {
+	seen := make(map[uint64][]*SpriteFrame, len(f.Frames))
+
+	for i, frame := range f.Frames {
+		h := frame.hash()
+		if original := findFrame(seen[h], frame); original != nil {
+			f.Frames[i] = original
+			continue
+		}
+
+		seen[h] = append(seen[h], frame)
+	}
+}
+
+func findFrame(candidates []*SpriteFrame, frame *SpriteFrame) *SpriteFrame {
+	for _, candidate := range candidates {
+		if candidate.equal(frame) {
+			return candidate
+		}
+	}
+
+	return nil
+}
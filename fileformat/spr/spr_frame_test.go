@@ -0,0 +1,84 @@
+package spr_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrim(t *testing.T) {
+	var tests = []struct {
+		Name     string
+		Frame    *spr.SpriteFrame
+		Expected *spr.SpriteFrame
+	}{
+		{
+			Name: "trim indexed frame",
+			Frame: &spr.SpriteFrame{SpriteType: spr.SpriteFileTypePAL, Width: 4, Height: 3, Data: []byte{
+				0, 0, 0, 0,
+				0, 0, 5, 6,
+				0, 0, 7, 0,
+			}},
+			Expected: &spr.SpriteFrame{
+				SpriteType: spr.SpriteFileTypePAL, Width: 2, Height: 2, Data: []byte{5, 6, 7, 0},
+				OffsetX: 2, OffsetY: 1, OriginalWidth: 4, OriginalHeight: 3,
+			},
+		},
+		{
+			Name: "trim bottom-up rgba frame",
+			Frame: &spr.SpriteFrame{SpriteType: spr.SpriteFileTypeRGBA, Width: 2, Height: 3, Data: []byte{
+				0x00, 0xff, 0xff, 0xff, 0xff, 0x01, 0x02, 0x03, // bottom row
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // top row
+			}},
+			Expected: &spr.SpriteFrame{
+				SpriteType: spr.SpriteFileTypeRGBA, Width: 1, Height: 1, Data: []byte{0xff, 0x01, 0x02, 0x03},
+				OffsetX: 1, OffsetY: 2, OriginalWidth: 2, OriginalHeight: 3,
+			},
+		},
+		{
+			Name:  "keep opaque frame",
+			Frame: &spr.SpriteFrame{SpriteType: spr.SpriteFileTypePAL, Width: 2, Height: 1, Data: []byte{1, 2}},
+			Expected: &spr.SpriteFrame{
+				SpriteType: spr.SpriteFileTypePAL, Width: 2, Height: 1, Data: []byte{1, 2},
+				OriginalWidth: 2, OriginalHeight: 1,
+			},
+		},
+		{
+			Name:  "empty fully transparent frame",
+			Frame: &spr.SpriteFrame{SpriteType: spr.SpriteFileTypePAL, Width: 2, Height: 2, Data: []byte{0, 0, 0, 0}},
+			Expected: &spr.SpriteFrame{
+				SpriteType: spr.SpriteFileTypePAL, Data: []byte{},
+				OriginalWidth: 2, OriginalHeight: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			tt.Frame.Trim()
+			assert.Equal(t, tt.Expected, tt.Frame)
+
+			tt.Frame.Trim()
+			assert.Equal(t, tt.Expected, tt.Frame)
+		})
+	}
+}
+
+func TestLoadWithTrimFrames(t *testing.T) {
+	data := sprtest.Encode([]sprtest.Frame{
+		{Width: 3, Height: 1, Data: []byte{0x00, 0x01, 0x04, 0x00, 0x01}},
+		{Width: 4, Height: 1, Data: []byte{0x04, 0x00, 0x03}},
+	})
+
+	file, err := spr.LoadWithOptions(bytes.NewReader(data), spr.LoadOptions{TrimFrames: true, DeduplicateFrames: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []byte{4}, file.Frames[0].Data)
+	assert.Equal(t, uintptr(1), file.Frames[0].OffsetX)
+	assert.Equal(t, []byte{4}, file.Frames[1].Data)
+	assert.Equal(t, uintptr(0), file.Frames[1].OffsetX)
+	assert.NotSame(t, file.Frames[0], file.Frames[1])
+}
@@ -0,0 +1,64 @@
+package spr
+
+// HitMask is a low resolution opacity mask of a frame, used to test whether
+// a point hits the visible pixels of a sprite rather than its bounding quad.
+type HitMask struct {
+	Width    int
+	Height   int
+	CellSize int
+
+	cells []bool
+}
+
+// HitMask builds a mask where each cell covers cellSize x cellSize pixels of
+// the frame, and is set when any of those pixels is not transparent.
+func (f *SpriteFrame) HitMask(cellSize int) *HitMask {
+	if cellSize < 1 {
+		cellSize = 1
+	}
+
+	var (
+		width  = int(f.Width)
+		height = int(f.Height)
+		mask   = &HitMask{
+			Width:    (width + cellSize - 1) / cellSize,
+			Height:   (height + cellSize - 1) / cellSize,
+			CellSize: cellSize,
+		}
+	)
+
+	mask.cells = make([]bool, mask.Width*mask.Height)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var opaque bool
+			if f.SpriteType == SpriteFileTypeRGBA {
+				// rgba frames are stored bottom-up, with alpha first
+				opaque = f.Data[((height-y-1)*width+x)*4] != 0
+			} else {
+				opaque = f.Data[y*width+x] != 0
+			}
+
+			if opaque {
+				mask.cells[(y/cellSize)*mask.Width+x/cellSize] = true
+			}
+		}
+	}
+
+	return mask
+}
+
+// Contains reports whether the frame pixel at x, y, counted from the top-left
+// corner, falls in an opaque cell.
+func (m *HitMask) Contains(x, y int) bool {
+	if x < 0 || y < 0 {
+		return false
+	}
+
+	cx, cy := x/m.CellSize, y/m.CellSize
+	if cx >= m.Width || cy >= m.Height {
+		return false
+	}
+
+	return m.cells[cy*m.Width+cx]
+}
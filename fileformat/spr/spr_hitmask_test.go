@@ -0,0 +1,42 @@
+package spr_test
+
+import (
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHitMask(t *testing.T) {
+	t.Run("indexed frame", func(t *testing.T) {
+		frame := &spr.SpriteFrame{SpriteType: spr.SpriteFileTypePAL, Width: 5, Height: 4, Data: []byte{
+			0, 0, 0, 0, 0,
+			0, 0, 0, 0, 0,
+			0, 0, 0, 0, 3,
+			0, 0, 0, 0, 0,
+		}}
+
+		mask := frame.HitMask(2)
+		assert.Equal(t, 3, mask.Width)
+		assert.Equal(t, 2, mask.Height)
+
+		assert.True(t, mask.Contains(4, 2))
+		assert.True(t, mask.Contains(4, 3))
+		assert.False(t, mask.Contains(3, 2))
+		assert.False(t, mask.Contains(4, 1))
+		assert.False(t, mask.Contains(0, 0))
+		assert.False(t, mask.Contains(-1, 2))
+		assert.False(t, mask.Contains(6, 2))
+	})
+
+	t.Run("bottom-up rgba frame", func(t *testing.T) {
+		frame := &spr.SpriteFrame{SpriteType: spr.SpriteFileTypeRGBA, Width: 1, Height: 2, Data: []byte{
+			0xff, 0x00, 0x00, 0x00, // bottom row
+			0x00, 0xff, 0xff, 0xff, // top row
+		}}
+
+		mask := frame.HitMask(1)
+		assert.False(t, mask.Contains(0, 0))
+		assert.True(t, mask.Contains(0, 1))
+	})
+}
@@ -0,0 +1,140 @@
+package spr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+
+	"github.com/project-midgard/midgarts/fileformat/pal"
+)
+
+// ConvertOptions controls how frames are converted into images.
+type ConvertOptions struct {
+	// PremultiplyAlpha returns an *image.RGBA, whose colors are multiplied
+	// by their alpha. Such images must be blended with (ONE,
+	// ONE_MINUS_SRC_ALPHA) instead of (SRC_ALPHA, ONE_MINUS_SRC_ALPHA), and
+	// don't show dark fringes when filtered.
+	PremultiplyAlpha bool
+
+	// ColorKey, when set, also makes the palette colors of indexed frames
+	// that are within ColorKeyTolerance of it, on every channel, transparent.
+	// Some legacy sprites paint their background in a near-magenta color
+	// instead of using index 0.
+	ColorKey          *color.NRGBA
+	ColorKeyTolerance uint8
+
+	// Scale enlarges the image 2 or 4 times with a pixel-art scaler, for
+	// HD previews and exports. Zero and 1 keep the original size.
+	Scale int
+}
+
+// ImageAt converts the frame at index into an RGBA image. Palette index 0 is
+// the transparent color of indexed frames.
+func (f *SpriteFile) ImageAt(index int) (*image.NRGBA, error) {
+	return f.convert(index, ConvertOptions{})
+}
+
+func (f *SpriteFile) convert(index int, opts ConvertOptions) (*image.NRGBA, error) {
+	if index < 0 || index >= len(f.Frames) {
+		return nil, fmt.Errorf("frame %d out of range", index)
+	}
+
+	var (
+		frame   = f.Frames[index]
+		width   = int(frame.Width)
+		height  = int(frame.Height)
+		img     = image.NewNRGBA(image.Rect(0, 0, width, height))
+		palette = f.Palette.Bytes()
+		keyed   = f.keyedIndices(opts)
+	)
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			dst := img.Pix[img.PixOffset(x, y):]
+
+			if frame.SpriteType == SpriteFileTypeRGBA {
+				src := frame.Data[((height-y-1)*width+x)*4:]
+				dst[0], dst[1], dst[2], dst[3] = src[3], src[2], src[1], src[0]
+				continue
+			}
+
+			i := int(frame.Data[y*width+x])
+			if keyed[i] {
+				continue
+			}
+
+			dst[0], dst[1], dst[2], dst[3] = palette[i*4], palette[i*4+1], palette[i*4+2], 0xff
+		}
+	}
+
+	return img, nil
+}
+
+// ConvertFrame converts the frame at index into an image, as configured by
+// opts.
+func (f *SpriteFile) ConvertFrame(index int, opts ConvertOptions) (image.Image, error) {
+	img, err := f.convert(index, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	switch opts.Scale {
+	case 0, 1:
+	case 2:
+		img = scale2x(img)
+	case 4:
+		img = scale2x(scale2x(img))
+	default:
+		return nil, fmt.Errorf("unsupported scale %d", opts.Scale)
+	}
+
+	if !opts.PremultiplyAlpha {
+		return img, nil
+	}
+
+	premultiplied := image.NewRGBA(img.Bounds())
+	draw.Draw(premultiplied, premultiplied.Bounds(), img, img.Bounds().Min, draw.Src)
+
+	return premultiplied, nil
+}
+
+// WithPalette returns a copy of the sprite sharing its frames, whose indexed
+// frames convert with p instead. Hair and clothes dyes are applied this way.
+func (f *SpriteFile) WithPalette(p *pal.Palette) *SpriteFile {
+	palette := bytes.NewBuffer(make([]byte, 0, PaletteSize))
+	_ = p.Save(palette)
+
+	c := *f
+	c.Palette = palette
+
+	return &c
+}
+
+// keyedIndices returns which palette indices convert to transparent pixels.
+func (f *SpriteFile) keyedIndices(opts ConvertOptions) (keyed [PaletteSize / 4]bool) {
+	keyed[0] = true
+
+	if opts.ColorKey == nil {
+		return keyed
+	}
+
+	palette := f.Palette.Bytes()
+	for i := range keyed {
+		keyed[i] = keyed[i] ||
+			withinTolerance(palette[i*4], opts.ColorKey.R, opts.ColorKeyTolerance) &&
+				withinTolerance(palette[i*4+1], opts.ColorKey.G, opts.ColorKeyTolerance) &&
+				withinTolerance(palette[i*4+2], opts.ColorKey.B, opts.ColorKeyTolerance)
+	}
+
+	return keyed
+}
+
+func withinTolerance(a, b, tolerance uint8) bool {
+	if a > b {
+		return a-b <= tolerance
+	}
+
+	return b-a <= tolerance
+}
@@ -0,0 +1,105 @@
+package spr
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// spriteFileJSON is the editable representation of a sprite file. Header
+// counts are derived from the frame list, and palette colors are written as
+// "#rrggbb" strings, or "#rrggbbxx" when the reserved fourth byte is set.
+type spriteFileJSON struct {
+	Version float32        `json:"version"`
+	Frames  []*SpriteFrame `json:"frames"`
+	Palette []string       `json:"palette"`
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (t FileType) MarshalText() ([]byte, error) {
+	switch t {
+	case SpriteFileTypePAL:
+		return []byte("indexed"), nil
+	case SpriteFileTypeRGBA:
+		return []byte("rgba"), nil
+	default:
+		return nil, fmt.Errorf("unknown frame type %d", t)
+	}
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (t *FileType) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "indexed":
+		*t = SpriteFileTypePAL
+	case "rgba":
+		*t = SpriteFileTypeRGBA
+	default:
+		return fmt.Errorf("unknown frame type %q", text)
+	}
+
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler.
+func (f *SpriteFile) MarshalJSON() ([]byte, error) {
+	out := spriteFileJSON{
+		Version: f.Header.Version,
+		Frames:  f.Frames,
+		Palette: make([]string, 0, PaletteSize/4),
+	}
+
+	if f.Palette != nil {
+		palette := f.Palette.Bytes()
+		for i := 0; i+3 < len(palette); i += 4 {
+			c := palette[i : i+3]
+			if palette[i+3] != 0 {
+				c = palette[i : i+4]
+			}
+
+			out.Palette = append(out.Palette, "#"+hex.EncodeToString(c))
+		}
+	}
+
+	return json.Marshal(out)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Indexed frames must come before
+// RGBA frames, as they do in .spr files.
+func (f *SpriteFile) UnmarshalJSON(data []byte) error {
+	var in spriteFileJSON
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+
+	if len(in.Palette) > PaletteSize/4 {
+		return fmt.Errorf("palette has %d colors, expected at most %d", len(in.Palette), PaletteSize/4)
+	}
+
+	palette := make([]byte, PaletteSize)
+	for i, c := range in.Palette {
+		rgb, err := hex.DecodeString(strings.TrimPrefix(c, "#"))
+		if err != nil || (len(rgb) != 3 && len(rgb) != 4) {
+			return fmt.Errorf("invalid palette color %d: %q", i, c)
+		}
+
+		copy(palette[i*4:], rgb)
+	}
+
+	indexedFrameCount, rgbaFrameCount, err := frameCounts(in.Frames)
+	if err != nil {
+		return err
+	}
+
+	f.Header.Signature = HeaderSignature
+	f.Header.Version = in.Version
+	f.Header.IndexedFrameCount = indexedFrameCount
+	f.Header.RGBAFrameCount = rgbaFrameCount
+	f.Header.RGBAIndex = indexedFrameCount
+	f.Frames = in.Frames
+	f.Palette = bytes.NewBuffer(palette)
+
+	return nil
+}
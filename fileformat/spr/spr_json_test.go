@@ -0,0 +1,63 @@
+package spr_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	palette := make([]byte, spr.PaletteSize)
+	copy(palette[4:], []byte{0xff, 0x80, 0x00, 0x00, 0x00, 0x00, 0xff, 0x01})
+
+	original := sprtest.EncodeWithRGBA(
+		[]sprtest.Frame{{Width: 2, Height: 1, Data: []byte{0x01, 0x00, 0x01}}},
+		[]sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0xff, 0x00, 0x00, 0xff}}},
+		palette,
+	)
+
+	file, err := spr.Load(bytes.NewReader(original))
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(file)
+	assert.NoError(t, err)
+
+	var fields map[string]interface{}
+	assert.NoError(t, json.Unmarshal(data, &fields))
+	assert.Equal(t, "#ff8000", fields["palette"].([]interface{})[1])
+	assert.Equal(t, "#0000ff01", fields["palette"].([]interface{})[2])
+	assert.Equal(t, "indexed", fields["frames"].([]interface{})[0].(map[string]interface{})["type"])
+
+	decoded := new(spr.SpriteFile)
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, file.Header, decoded.Header)
+	assert.Equal(t, file.Frames, decoded.Frames)
+	assert.Equal(t, file.Palette.Bytes(), decoded.Palette.Bytes())
+
+	buf := new(bytes.Buffer)
+	assert.NoError(t, decoded.Save(buf))
+	assert.Equal(t, original, buf.Bytes())
+}
+
+func TestJSONErrors(t *testing.T) {
+	var tests = []struct {
+		Name string
+		Data string
+	}{
+		{Name: "unknown frame type", Data: `{"frames":[{"type":"foo","width":1,"height":1,"data":"AA=="}]}`},
+		{Name: "data size mismatch", Data: `{"frames":[{"type":"indexed","width":2,"height":1,"data":"AA=="}]}`},
+		{Name: "frame too large", Data: `{"frames":[{"type":"indexed","width":4294967296,"height":4294967296,"data":""}]}`},
+		{Name: "indexed after rgba", Data: `{"frames":[{"type":"rgba","width":1,"height":1,"data":"AAAAAA=="},{"type":"indexed","width":1,"height":1,"data":"AA=="}]}`},
+		{Name: "invalid palette color", Data: `{"frames":[],"palette":["#zz0000"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			assert.Error(t, json.Unmarshal([]byte(tt.Data), new(spr.SpriteFile)))
+		})
+	}
+}
@@ -0,0 +1,233 @@
+package spr
+
+import (
+	"image"
+	"image/color"
+	"sort"
+
+	"github.com/project-midgard/midgarts/fileformat/pal"
+)
+
+const (
+	// quantizeAlphaThreshold is the alpha below which a pixel becomes transparent.
+	quantizeAlphaThreshold = 0x80
+)
+
+// TransparentColor is written at palette index 0, which indexed frames treat
+// as transparent.
+var TransparentColor = color.NRGBA{R: 0xff, G: 0x00, B: 0xff, A: 0xff}
+
+// Quantize converts an image into an indexed frame and the palette it uses.
+// Pixels with less than half opacity map to index 0; the remaining colors are
+// reduced to at most 255 entries using median cut.
+func Quantize(img image.Image) (*SpriteFrame, *pal.Palette) {
+	var (
+		bounds    = img.Bounds()
+		histogram = make(map[color.NRGBA]int)
+		pixels    = make([]color.NRGBA, 0, bounds.Dx()*bounds.Dy())
+	)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < quantizeAlphaThreshold {
+				c = color.NRGBA{}
+			} else {
+				c.A = 0xff
+				histogram[c]++
+			}
+
+			pixels = append(pixels, c)
+		}
+	}
+
+	var (
+		palette = new(pal.Palette)
+		colors  = medianCut(histogram, pal.ColorCount-1)
+		indices = make(map[color.NRGBA]byte, len(histogram))
+		frame   = &SpriteFrame{
+			SpriteType: SpriteFileTypePAL,
+			Width:      uintptr(bounds.Dx()),
+			Height:     uintptr(bounds.Dy()),
+			Data:       make([]byte, len(pixels)),
+		}
+	)
+
+	palette.Colors[0] = TransparentColor
+	copy(palette.Colors[1:], colors)
+
+	for i, c := range pixels {
+		if c.A == 0 {
+			continue
+		}
+
+		index, ok := indices[c]
+		if !ok {
+			index = nearestColor(colors, c) + 1
+			indices[c] = index
+		}
+
+		frame.Data[i] = index
+	}
+
+	return frame, palette
+}
+
+// colorBox is a set of histogram colors handled as a single median cut bucket.
+type colorBox struct {
+	colors []color.NRGBA
+	counts []int
+}
+
+// medianCut reduces the histogram to at most n colors.
+func medianCut(histogram map[color.NRGBA]int, n int) []color.NRGBA {
+	box := colorBox{}
+	for c := range histogram {
+		box.colors = append(box.colors, c)
+	}
+
+	// keep the output stable, map iteration order is random
+	sort.Slice(box.colors, func(i, j int) bool {
+		a, b := box.colors[i], box.colors[j]
+		return uint32(a.R)<<16|uint32(a.G)<<8|uint32(a.B) < uint32(b.R)<<16|uint32(b.G)<<8|uint32(b.B)
+	})
+
+	for _, c := range box.colors {
+		box.counts = append(box.counts, histogram[c])
+	}
+
+	if len(box.colors) <= n {
+		return box.colors
+	}
+
+	boxes := []colorBox{box}
+	for len(boxes) < n {
+		widest, channel, width := -1, 0, 0
+		for i, b := range boxes {
+			if len(b.colors) < 2 {
+				continue
+			}
+
+			if c, w := b.widestChannel(); w > width {
+				widest, channel, width = i, c, w
+			}
+		}
+
+		if widest < 0 {
+			break
+		}
+
+		a, b := boxes[widest].split(channel)
+		boxes[widest] = a
+		boxes = append(boxes, b)
+	}
+
+	colors := make([]color.NRGBA, len(boxes))
+	for i, b := range boxes {
+		colors[i] = b.average()
+	}
+
+	return colors
+}
+
+func channelValue(c color.NRGBA, channel int) int {
+	switch channel {
+	case 0:
+		return int(c.R)
+	case 1:
+		return int(c.G)
+	default:
+		return int(c.B)
+	}
+}
+
+// widestChannel returns the channel with the largest value range and its width.
+func (b colorBox) widestChannel() (channel, width int) {
+	for ch := 0; ch < 3; ch++ {
+		min, max := 0xff, 0
+		for _, c := range b.colors {
+			v := channelValue(c, ch)
+			if v < min {
+				min = v
+			}
+			if v > max {
+				max = v
+			}
+		}
+
+		if max-min > width {
+			channel, width = ch, max-min
+		}
+	}
+
+	return channel, width
+}
+
+// split divides the box at the weighted median of the given channel.
+func (b colorBox) split(channel int) (colorBox, colorBox) {
+	sort.Sort(boxSorter{b, channel})
+
+	total := 0
+	for _, count := range b.counts {
+		total += count
+	}
+
+	median, sum := 1, 0
+	for i, count := range b.counts[:len(b.counts)-1] {
+		sum += count
+		median = i + 1
+		if sum*2 >= total {
+			break
+		}
+	}
+
+	return colorBox{b.colors[:median], b.counts[:median]},
+		colorBox{b.colors[median:], b.counts[median:]}
+}
+
+func (b colorBox) average() color.NRGBA {
+	var r, g, bl, total int
+	for i, c := range b.colors {
+		r += int(c.R) * b.counts[i]
+		g += int(c.G) * b.counts[i]
+		bl += int(c.B) * b.counts[i]
+		total += b.counts[i]
+	}
+
+	return color.NRGBA{
+		R: uint8((r + total/2) / total),
+		G: uint8((g + total/2) / total),
+		B: uint8((bl + total/2) / total),
+		A: 0xff,
+	}
+}
+
+type boxSorter struct {
+	colorBox
+	channel int
+}
+
+func (s boxSorter) Len() int {
+	return len(s.colors)
+}
+
+func (s boxSorter) Less(i, j int) bool {
+	return channelValue(s.colors[i], s.channel) < channelValue(s.colors[j], s.channel)
+}
+
+func (s boxSorter) Swap(i, j int) {
+	s.colors[i], s.colors[j] = s.colors[j], s.colors[i]
+	s.counts[i], s.counts[j] = s.counts[j], s.counts[i]
+}
+
+func nearestColor(colors []color.NRGBA, c color.NRGBA) byte {
+	best, bestDistance := 0, -1
+	for i, p := range colors {
+		dr, dg, db := int(p.R)-int(c.R), int(p.G)-int(c.G), int(p.B)-int(c.B)
+		if d := dr*dr + dg*dg + db*db; bestDistance < 0 || d < bestDistance {
+			best, bestDistance = i, d
+		}
+	}
+
+	return byte(best)
+}
@@ -0,0 +1,57 @@
+package spr_test
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQuantize(t *testing.T) {
+	t.Run("keep exact colors when they fit the palette", func(t *testing.T) {
+		img := image.NewNRGBA(image.Rect(0, 0, 3, 2))
+		img.SetNRGBA(0, 0, color.NRGBA{R: 0xff, A: 0xff})
+		img.SetNRGBA(1, 0, color.NRGBA{G: 0xff, A: 0xff})
+		img.SetNRGBA(2, 0, color.NRGBA{R: 0xff, A: 0xff})
+		img.SetNRGBA(0, 1, color.NRGBA{B: 0xff, A: 0x40})
+		img.SetNRGBA(1, 1, color.NRGBA{B: 0xff, A: 0xc0})
+
+		frame, palette := spr.Quantize(img)
+		assert.Equal(t, spr.SpriteFileTypePAL, frame.SpriteType)
+		assert.Equal(t, uintptr(3), frame.Width)
+		assert.Equal(t, uintptr(2), frame.Height)
+		assert.Equal(t, spr.TransparentColor, palette.Colors[0])
+
+		for i, expected := range []color.NRGBA{
+			{R: 0xff, A: 0xff}, {G: 0xff, A: 0xff}, {R: 0xff, A: 0xff},
+			{}, {B: 0xff, A: 0xff}, {},
+		} {
+			if expected.A == 0 {
+				assert.Equal(t, byte(0), frame.Data[i])
+				continue
+			}
+
+			assert.NotEqual(t, byte(0), frame.Data[i])
+			assert.Equal(t, expected, palette.Colors[frame.Data[i]])
+		}
+	})
+
+	t.Run("reduce gradients to 255 colors", func(t *testing.T) {
+		img := image.NewGray16(image.Rect(0, 0, 64, 64))
+		for y := 0; y < 64; y++ {
+			for x := 0; x < 64; x++ {
+				img.SetGray16(x, y, color.Gray16{Y: uint16((y*64 + x) * 16)})
+			}
+		}
+
+		frame, palette := spr.Quantize(img)
+		for i, index := range frame.Data {
+			assert.NotEqual(t, byte(0), index)
+
+			expected := color.NRGBAModel.Convert(img.At(i%64, i/64)).(color.NRGBA)
+			assert.InDelta(t, expected.R, palette.Colors[index].R, 2)
+		}
+	})
+}
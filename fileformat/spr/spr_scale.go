@@ -0,0 +1,76 @@
+package spr
+
+import (
+	"image"
+)
+
+// scale2x doubles the size of img with the Scale2x (EPX) pixel-art scaler,
+// which rounds diagonal edges instead of repeating pixels as blocks.
+func scale2x(img *image.NRGBA) *image.NRGBA {
+	var (
+		width  = img.Rect.Dx()
+		height = img.Rect.Dy()
+		out    = image.NewNRGBA(image.Rect(0, 0, width*2, height*2))
+	)
+
+	// at returns the pixel at (x, y), clamped to the image bounds.
+	at := func(x, y int) [4]byte {
+		x = clamp(x, 0, width-1)
+		y = clamp(y, 0, height-1)
+
+		var c [4]byte
+		copy(c[:], img.Pix[img.PixOffset(img.Rect.Min.X+x, img.Rect.Min.Y+y):])
+
+		return c
+	}
+
+	set := func(x, y int, c [4]byte) {
+		copy(out.Pix[out.PixOffset(x, y):], c[:])
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var (
+				p = at(x, y)
+				a = at(x, y-1)
+				b = at(x+1, y)
+				c = at(x-1, y)
+				d = at(x, y+1)
+
+				e0, e1, e2, e3 = p, p, p, p
+			)
+
+			if c == a && c != d && a != b {
+				e0 = a
+			}
+			if a == b && a != c && b != d {
+				e1 = b
+			}
+			if d == c && d != b && c != a {
+				e2 = c
+			}
+			if b == d && b != a && d != c {
+				e3 = d
+			}
+
+			set(x*2, y*2, e0)
+			set(x*2+1, y*2, e1)
+			set(x*2, y*2+1, e2)
+			set(x*2+1, y*2+1, e3)
+		}
+	}
+
+	return out
+}
+
+func clamp(v, min, max int) int {
+	if v < min {
+		return min
+	}
+
+	if v > max {
+		return max
+	}
+
+	return v
+}
@@ -0,0 +1,61 @@
+package spr_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertFrameWithScale(t *testing.T) {
+	palette := make([]byte, spr.PaletteSize)
+	copy(palette[4:], []byte{0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0x00})
+
+	file, err := spr.Load(bytes.NewReader(sprtest.EncodeWithRGBA(
+		[]sprtest.Frame{{Width: 2, Height: 2, Data: []byte{0x01, 0x02, 0x02, 0x01}}},
+		nil,
+		palette,
+	)))
+	assert.NoError(t, err)
+
+	var tests = []struct {
+		Name     string
+		Scale    int
+		Expected [][]byte
+	}{
+		{Name: "original size", Scale: 1, Expected: [][]byte{
+			{1, 2},
+			{2, 1},
+		}},
+		{Name: "2x rounds the diagonal", Scale: 2, Expected: [][]byte{
+			{1, 1, 2, 2},
+			{1, 2, 1, 2},
+			{2, 1, 2, 1},
+			{2, 2, 1, 1},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			img, err := file.ConvertFrame(0, spr.ConvertOptions{Scale: tt.Scale})
+			assert.NoError(t, err)
+			assert.Equal(t, len(tt.Expected), img.Bounds().Dy())
+
+			for y, row := range tt.Expected {
+				for x, i := range row {
+					r, _, b, _ := img.At(x, y).RGBA()
+					assert.Equal(t, i == 1, r > 0 && b == 0, "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+
+	img, err := file.ConvertFrame(0, spr.ConvertOptions{Scale: 4})
+	assert.NoError(t, err)
+	assert.Equal(t, 8, img.Bounds().Dx())
+
+	_, err = file.ConvertFrame(0, spr.ConvertOptions{Scale: 3})
+	assert.Error(t, err)
+}
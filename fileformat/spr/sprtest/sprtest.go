@@ -0,0 +1,46 @@
+// Package sprtest builds sprite files for tests.
+package sprtest
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/project-midgard/midgarts/fileformat/spr"
+)
+
+// Frame is a frame as stored in the file: RLE-encoded palette indices for
+// indexed frames, or bottom-up ABGR pixels for RGBA frames.
+type Frame struct {
+	Width, Height uint16
+	Data          []byte
+}
+
+// Encode builds a version 2.1 sprite file with RLE-encoded indexed frames
+// and a blank palette.
+func Encode(frames []Frame) []byte {
+	return EncodeWithRGBA(frames, nil, make([]byte, spr.PaletteSize))
+}
+
+// EncodeWithRGBA builds a version 2.1 sprite file with indexed frames, RGBA
+// frames and the given palette.
+func EncodeWithRGBA(frames, rgbaFrames []Frame, palette []byte) []byte {
+	buf := new(bytes.Buffer)
+
+	buf.WriteString("SP")
+	buf.Write([]byte{1, 2})
+	_ = binary.Write(buf, binary.LittleEndian, []uint16{uint16(len(frames)), uint16(len(rgbaFrames))})
+
+	for _, frame := range frames {
+		_ = binary.Write(buf, binary.LittleEndian, []uint16{frame.Width, frame.Height, uint16(len(frame.Data))})
+		buf.Write(frame.Data)
+	}
+
+	for _, frame := range rgbaFrames {
+		_ = binary.Write(buf, binary.LittleEndian, []uint16{frame.Width, frame.Height})
+		buf.Write(frame.Data)
+	}
+
+	buf.Write(palette)
+
+	return buf.Bytes()
+}
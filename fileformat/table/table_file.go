@@ -0,0 +1,73 @@
+package table
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Parse parses an id table such as idnum2itemdisplaynametable.txt, made
+// of "id#text#" pairs. Text may span several lines. Lines starting with "//"
+// are comments, and so is anything after "//" between two entries.
+func Parse(text string) (map[int]string, error) {
+	fields := strings.Split(stripCommentLines(text), "#")
+	table := make(map[int]string, len(fields)/2)
+
+	for i := 0; i+1 < len(fields); i += 2 {
+		key := stripTrailingComments(fields[i])
+
+		id, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid id '%s'", key)
+		}
+
+		table[id] = strings.Trim(fields[i+1], "\r\n")
+	}
+
+	return table, nil
+}
+
+// ParseMessages parses a message table such as msgstringtable.txt, where
+// every message ends with a "#" and is identified by its position.
+func ParseMessages(text string) []string {
+	fields := strings.Split(text, "#")
+
+	messages := make([]string, 0, len(fields))
+	for _, field := range fields[:len(fields)-1] {
+		messages = append(messages, strings.TrimLeft(field, "\r\n"))
+	}
+
+	return messages
+}
+
+// stripCommentLines removes lines starting with "//", which may hold
+// disabled entries.
+func stripCommentLines(text string) string {
+	lines := strings.Split(text, "\n")
+
+	kept := lines[:0]
+	for _, line := range lines {
+		if !strings.HasPrefix(strings.TrimSpace(line), "//") {
+			kept = append(kept, line)
+		}
+	}
+
+	return strings.Join(kept, "\n")
+}
+
+// stripTrailingComments removes comments and whitespace from the text
+// between two table entries.
+func stripTrailingComments(text string) string {
+	var b strings.Builder
+
+	for _, line := range strings.Split(text, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+
+		b.WriteString(strings.TrimSpace(line))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,22 @@
+package table_test
+
+import (
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/table"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	entries, err := table.Parse("// comment\r\n//500#Disabled#\r\n501#Red_Potion# // 1\r\n502#Orange\r\nPotion#\r\n")
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]string{501: "Red_Potion", 502: "Orange\r\nPotion"}, entries)
+
+	_, err = table.Parse("abc#Red_Potion#")
+	assert.Error(t, err)
+}
+
+func TestParseMessages(t *testing.T) {
+	assert.Equal(t, []string{"Ok", "", "Cancel"}, table.ParseMessages("Ok#\r\n#\r\nCancel#\r\n"))
+	assert.Empty(t, table.ParseMessages(""))
+}
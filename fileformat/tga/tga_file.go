@@ -0,0 +1,261 @@
+package tga
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"io"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+type imageType byte
+
+const (
+	imageTypeColorMapped    imageType = 1
+	imageTypeTrueColor      imageType = 2
+	imageTypeGrayscale      imageType = 3
+	imageTypeRLEColorMapped imageType = 9
+	imageTypeRLETrueColor   imageType = 10
+	imageTypeRLEGrayscale   imageType = 11
+
+	descriptorAlphaBits   = 0x0f
+	descriptorRightToLeft = 0x10
+	descriptorTopToBottom = 0x20
+)
+
+// File is a decoded Truevision TGA image.
+type File struct {
+	Header struct {
+		IDLength        byte
+		ColorMapType    byte
+		ImageType       imageType
+		ColorMapOrigin  uint16
+		ColorMapLength  uint16
+		ColorMapDepth   byte
+		XOrigin         uint16
+		YOrigin         uint16
+		Width           uint16
+		Height          uint16
+		PixelDepth      byte
+		ImageDescriptor byte
+	}
+
+	Image *image.NRGBA
+}
+
+// Load decodes a TGA image. Color-mapped, true-color and grayscale images are
+// supported, either raw or run-length encoded.
+func Load(buf io.Reader) (file *File, err error) {
+	file = new(File)
+
+	if err = binary.Read(buf, binary.LittleEndian, &file.Header); err != nil {
+		return nil, errors.Wrap(err, "could not read header")
+	}
+
+	if _, err = io.CopyN(ioutil.Discard, buf, int64(file.Header.IDLength)); err != nil {
+		return nil, errors.Wrap(err, "could not skip image id")
+	}
+
+	var colorMap [][4]byte
+	if file.Header.ColorMapType == 1 {
+		if colorMap, err = file.readColorMap(buf); err != nil {
+			return nil, err
+		}
+	}
+
+	pixels, err := file.readPixels(buf)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = file.decodeImage(pixels, colorMap); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (f *File) readColorMap(buf io.Reader) ([][4]byte, error) {
+	depth := int(f.Header.ColorMapDepth)
+	if !isSupportedDepth(depth) {
+		return nil, fmt.Errorf("unsupported color map depth %d", depth)
+	}
+
+	data := make([]byte, int(f.Header.ColorMapLength)*((depth+7)/8))
+	if _, err := io.ReadFull(buf, data); err != nil {
+		return nil, errors.Wrap(err, "could not read color map")
+	}
+
+	colorMap := make([][4]byte, int(f.Header.ColorMapOrigin)+int(f.Header.ColorMapLength))
+	for i, step := 0, (depth+7)/8; i < int(f.Header.ColorMapLength); i++ {
+		colorMap[int(f.Header.ColorMapOrigin)+i] = f.decodeColor(data[i*step:(i+1)*step], depth)
+	}
+
+	return colorMap, nil
+}
+
+// readPixels returns the raw pixel data, expanding run-length packets.
+func (f *File) readPixels(buf io.Reader) ([]byte, error) {
+	var (
+		depth = int(f.Header.PixelDepth)
+		step  = (depth + 7) / 8
+		size  = int(f.Header.Width) * int(f.Header.Height) * step
+	)
+
+	switch f.Header.ImageType {
+	case imageTypeColorMapped, imageTypeRLEColorMapped:
+		if f.Header.ColorMapType != 1 || depth != 8 {
+			return nil, fmt.Errorf("unsupported color-mapped pixel depth %d", depth)
+		}
+	case imageTypeGrayscale, imageTypeRLEGrayscale:
+		if depth != 8 && depth != 16 {
+			return nil, fmt.Errorf("unsupported grayscale pixel depth %d", depth)
+		}
+	case imageTypeTrueColor, imageTypeRLETrueColor:
+		if !isSupportedDepth(depth) {
+			return nil, fmt.Errorf("unsupported pixel depth %d", depth)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported image type %d", f.Header.ImageType)
+	}
+
+	data, err := ioutil.ReadAll(buf)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read pixel data")
+	}
+
+	// Sizes come from the header, so they are checked against the input
+	// before anything is allocated.
+	if f.Header.ImageType < imageTypeRLEColorMapped {
+		if len(data) < size {
+			return nil, errors.Wrap(io.ErrUnexpectedEOF, "could not read pixel data")
+		}
+
+		return data[:size], nil
+	}
+
+	// A packet takes at least 1+step bytes and expands to at most 128 pixels.
+	if size > len(data)/(1+step)*128*step {
+		return nil, errors.New("rle data too short for image size")
+	}
+
+	var (
+		pixels = make([]byte, size)
+		packet [1]byte
+	)
+
+	buf = bytes.NewReader(data)
+	for offset := 0; offset < size; {
+		if _, err := io.ReadFull(buf, packet[:]); err != nil {
+			return nil, errors.Wrap(err, "could not read rle packet")
+		}
+
+		count := int(packet[0]&0x7f) + 1
+		if offset+count*step > size {
+			return nil, errors.New("rle packet overflows image")
+		}
+
+		if packet[0]&0x80 == 0 {
+			if _, err := io.ReadFull(buf, pixels[offset:offset+count*step]); err != nil {
+				return nil, errors.Wrap(err, "could not read rle raw packet")
+			}
+
+			offset += count * step
+			continue
+		}
+
+		if _, err := io.ReadFull(buf, pixels[offset:offset+step]); err != nil {
+			return nil, errors.Wrap(err, "could not read rle run packet")
+		}
+
+		for i := 1; i < count; i++ {
+			copy(pixels[offset+i*step:], pixels[offset:offset+step])
+		}
+
+		offset += count * step
+	}
+
+	return pixels, nil
+}
+
+func (f *File) decodeImage(pixels []byte, colorMap [][4]byte) error {
+	var (
+		width  = int(f.Header.Width)
+		height = int(f.Header.Height)
+		step   = (int(f.Header.PixelDepth) + 7) / 8
+	)
+
+	f.Image = image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			var (
+				src   = pixels[(y*width+x)*step : (y*width+x+1)*step]
+				color [4]byte
+			)
+
+			switch f.Header.ImageType {
+			case imageTypeColorMapped, imageTypeRLEColorMapped:
+				if int(src[0]) >= len(colorMap) {
+					return fmt.Errorf("color map index %d out of range", src[0])
+				}
+				color = colorMap[src[0]]
+			case imageTypeGrayscale, imageTypeRLEGrayscale:
+				color = [4]byte{src[0], src[0], src[0], 0xff}
+				if step == 2 {
+					color[3] = src[1]
+				}
+			default:
+				color = f.decodeColor(src, int(f.Header.PixelDepth))
+			}
+
+			dx, dy := x, y
+			if f.Header.ImageDescriptor&descriptorRightToLeft != 0 {
+				dx = width - x - 1
+			}
+			if f.Header.ImageDescriptor&descriptorTopToBottom == 0 {
+				dy = height - y - 1
+			}
+
+			copy(f.Image.Pix[f.Image.PixOffset(dx, dy):], color[:])
+		}
+	}
+
+	return nil
+}
+
+// decodeColor converts a BGR(A) or ARGB1555 color into RGBA.
+func (f *File) decodeColor(src []byte, depth int) [4]byte {
+	switch depth {
+	case 15, 16:
+		v := binary.LittleEndian.Uint16(src)
+		color := [4]byte{
+			expand5(byte(v >> 10)),
+			expand5(byte(v >> 5)),
+			expand5(byte(v)),
+			0xff,
+		}
+
+		if depth == 16 && f.Header.ImageDescriptor&descriptorAlphaBits != 0 && v&0x8000 == 0 {
+			color[3] = 0
+		}
+
+		return color
+	case 24:
+		return [4]byte{src[2], src[1], src[0], 0xff}
+	default:
+		return [4]byte{src[2], src[1], src[0], src[3]}
+	}
+}
+
+func expand5(v byte) byte {
+	v &= 0x1f
+	return v<<3 | v>>2
+}
+
+func isSupportedDepth(depth int) bool {
+	return depth == 15 || depth == 16 || depth == 24 || depth == 32
+}
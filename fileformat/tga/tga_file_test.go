@@ -0,0 +1,95 @@
+package tga_test
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/project-midgard/midgarts/fileformat/tga"
+	"github.com/stretchr/testify/assert"
+)
+
+func header(imageType, colorMapType byte, colorMapLength uint16, colorMapDepth byte, width, height uint16, depth, descriptor byte) []byte {
+	return []byte{
+		0, colorMapType, imageType,
+		0, 0, byte(colorMapLength), byte(colorMapLength >> 8), colorMapDepth,
+		0, 0, 0, 0,
+		byte(width), byte(width >> 8), byte(height), byte(height >> 8),
+		depth, descriptor,
+	}
+}
+
+func TestLoad(t *testing.T) {
+	var (
+		red   = color.NRGBA{R: 0xff, A: 0xff}
+		green = color.NRGBA{G: 0xff, A: 0xff}
+		blue  = color.NRGBA{B: 0xff, A: 0x80}
+		gray  = color.NRGBA{R: 0x40, G: 0x40, B: 0x40, A: 0xff}
+	)
+
+	var tests = []struct {
+		Name     string
+		Data     []byte
+		Expected [2]color.NRGBA
+	}{
+		{
+			Name:     "load bottom-up true color image",
+			Data:     append(header(2, 0, 0, 0, 1, 2, 24, 0), 0x00, 0xff, 0x00, 0x00, 0x00, 0xff),
+			Expected: [2]color.NRGBA{red, green},
+		},
+		{
+			Name:     "load top-down true color image with alpha",
+			Data:     append(header(2, 0, 0, 0, 1, 2, 32, 0x28), 0xff, 0x00, 0x00, 0x80, 0x00, 0xff, 0x00, 0xff),
+			Expected: [2]color.NRGBA{blue, green},
+		},
+		{
+			Name:     "load run-length encoded true color image",
+			Data:     append(header(10, 0, 0, 0, 1, 2, 24, 0x20), 0x81, 0x00, 0x00, 0xff),
+			Expected: [2]color.NRGBA{red, red},
+		},
+		{
+			Name: "load color-mapped image",
+			Data: append(
+				header(1, 1, 2, 24, 1, 2, 8, 0x20),
+				0x00, 0x00, 0xff, 0x00, 0xff, 0x00, // color map
+				0x01, 0x00,
+			),
+			Expected: [2]color.NRGBA{green, red},
+		},
+		{
+			Name:     "load grayscale image",
+			Data:     append(header(3, 0, 0, 0, 1, 2, 8, 0x20), 0x40, 0x40),
+			Expected: [2]color.NRGBA{gray, gray},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			file, err := tga.Load(bytes.NewReader(tt.Data))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected[0], file.Image.NRGBAAt(0, 0))
+			assert.Equal(t, tt.Expected[1], file.Image.NRGBAAt(0, 1))
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	var tests = []struct {
+		Name string
+		Data []byte
+	}{
+		{Name: "truncated header", Data: []byte{0, 0, 2}},
+		{Name: "truncated pixel data", Data: append(header(2, 0, 0, 0, 2, 2, 24, 0), 0x00)},
+		{Name: "truncated large image", Data: header(2, 0, 0, 0, 65535, 65535, 32, 0)},
+		{Name: "truncated large rle image", Data: append(header(10, 0, 0, 0, 65535, 65535, 32, 0), 0xff, 0x00, 0x00, 0x00, 0xff)},
+		{Name: "unsupported image type", Data: header(32, 0, 0, 0, 1, 1, 24, 0)},
+		{Name: "rle packet overflow", Data: append(header(10, 0, 0, 0, 1, 1, 24, 0), 0x85, 0x00, 0x00, 0xff)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			_, err := tga.Load(bytes.NewReader(tt.Data))
+			assert.Error(t, err)
+		})
+	}
+}
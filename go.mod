@@ -1,8 +1,9 @@
 module github.com/project-midgard/midgarts
 
-go 1.15
+go 1.16
 
 require (
 	github.com/pkg/errors v0.9.1
 	github.com/stretchr/testify v1.6.1
+	golang.org/x/text v0.3.4
 )
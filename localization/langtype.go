@@ -0,0 +1,61 @@
+package localization
+
+import (
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+	"golang.org/x/text/encoding/korean"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// LangType is the service type a client is configured for, as sent in
+// clientinfo.xml. It decides the encoding of text tables and of strings
+// received from the server.
+type LangType int
+
+const (
+	LangTypeKorea       LangType = 0
+	LangTypeAmerica     LangType = 1
+	LangTypeJapan       LangType = 2
+	LangTypeChina       LangType = 3
+	LangTypeTaiwan      LangType = 4
+	LangTypeThailand    LangType = 5
+	LangTypeIndonesia   LangType = 6
+	LangTypePhilippines LangType = 7
+	LangTypeMalaysia    LangType = 8
+	LangTypeSingapore   LangType = 9
+	LangTypeGermany     LangType = 10
+	LangTypeIndia       LangType = 11
+	LangTypeBrazil      LangType = 12
+	LangTypeAustralia   LangType = 13
+	LangTypeRussia      LangType = 14
+	LangTypeVietnam     LangType = 15
+	LangTypeChile       LangType = 17
+	LangTypeFrance      LangType = 18
+)
+
+// Encoding returns the code page used for text by clients of this langtype.
+// Unknown langtypes fall back to Windows-1252, like most western services.
+//
+// GRF entry names are always EUC-KR, regardless of the langtype.
+func (l LangType) Encoding() encoding.Encoding {
+	switch l {
+	case LangTypeKorea:
+		return korean.EUCKR
+	case LangTypeJapan:
+		return japanese.ShiftJIS
+	case LangTypeChina:
+		return simplifiedchinese.GBK
+	case LangTypeTaiwan:
+		return traditionalchinese.Big5
+	case LangTypeThailand:
+		return charmap.Windows874
+	case LangTypeRussia:
+		return charmap.Windows1251
+	case LangTypeVietnam:
+		return charmap.Windows1258
+	default:
+		return charmap.Windows1252
+	}
+}
@@ -0,0 +1,78 @@
+package localization_test
+
+import (
+	"io/fs"
+	"path"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/project-midgard/midgarts/localization"
+	"github.com/project-midgard/midgarts/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dataPath = "./../data"
+)
+
+func TestLangTypeEncoding(t *testing.T) {
+	var tests = []struct {
+		Name     string
+		LangType localization.LangType
+		Data     []byte
+		Expected string
+	}{
+		{Name: "korea", LangType: localization.LangTypeKorea, Data: []byte("\xc0\xaf\xc0\xfa"), Expected: "유저"},
+		{Name: "japan", LangType: localization.LangTypeJapan, Data: []byte("\x83\x41"), Expected: "ア"},
+		{Name: "america", LangType: localization.LangTypeAmerica, Data: []byte("Caf\xe9"), Expected: "Café"},
+		{Name: "russia", LangType: localization.LangTypeRussia, Data: []byte("\xcc\xe5\xf7"), Expected: "Меч"},
+		{Name: "unknown falls back to 1252", LangType: 99, Data: []byte("\xfc"), Expected: "ü"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			l := localization.NewLocalizer(tt.LangType, nil, nil)
+			text, err := l.Decode(tt.Data)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.Expected, text)
+		})
+	}
+}
+
+func TestLocalizerTable(t *testing.T) {
+	f, err := grf.NewFile(path.Join(dataPath, "custom.grf"))
+	assert.NoError(t, err)
+
+	overrides := fstest.MapFS{
+		"data/idnum2itemdisplaynametable.txt": {Data: []byte("2101#Escudo#\r\n")},
+	}
+
+	l := localization.NewLocalizer(localization.LangTypeBrazil, resource.NewGRFSource(f), resource.FSSource{FS: overrides})
+
+	table, err := l.Table("data\\idnum2itemdisplaynametable.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, "Escudo", table[2101])
+	assert.Equal(t, "Buckler", table[2103])
+
+	_, err = l.Table("data\\missing.txt")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestLocalizerMessages(t *testing.T) {
+	source := resource.FSSource{FS: fstest.MapFS{
+		"data/msgstringtable.txt": {Data: []byte("\xc8\xae\xc0\xce#\r\n\xc3\xeb\xbc\xd2#\r\n")},
+	}}
+	overrides := resource.FSSource{FS: fstest.MapFS{
+		"data/msgstringtable.txt": {Data: []byte("Ok#\r\n#\r\nExtra#\r\n")},
+	}}
+
+	messages, err := localization.NewLocalizer(localization.LangTypeKorea, source, nil).Messages("data\\msgstringtable.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"확인", "취소"}, messages)
+
+	messages, err = localization.NewLocalizer(localization.LangTypeKorea, source, overrides).Messages("data\\msgstringtable.txt")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Ok", "취소", "Extra"}, messages)
+}
@@ -0,0 +1,113 @@
+package localization
+
+import (
+	"io/fs"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/table"
+	"github.com/project-midgard/midgarts/resource"
+)
+
+// Localizer reads text tables in the encoding of its langtype, with optional
+// UTF-8 translations taking precedence over the game data.
+type Localizer struct {
+	LangType LangType
+
+	source    resource.Source
+	overrides resource.Source
+}
+
+// NewLocalizer returns a Localizer reading tables from source. Files found in
+// overrides, usually an FSSource over a translation directory laid out like
+// the data folder, are read as UTF-8 and replace the entries they define.
+// overrides may be nil.
+func NewLocalizer(langType LangType, source, overrides resource.Source) *Localizer {
+	return &Localizer{
+		LangType:  langType,
+		source:    source,
+		overrides: overrides,
+	}
+}
+
+// Decode converts text received in the langtype encoding, such as names sent
+// by the server, to UTF-8.
+func (l *Localizer) Decode(b []byte) (string, error) {
+	out, err := l.LangType.Encoding().NewDecoder().Bytes(b)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// Table loads the id table name, e.g. "data\idnum2itemdisplaynametable.txt".
+// Entries from the override table replace the original ones. It fails only
+// when neither exists.
+func (l *Localizer) Table(name string) (map[int]string, error) {
+	base, override, err := l.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(map[int]string)
+	for _, text := range []string{base, override} {
+		entries, err := table.Parse(text)
+		if err != nil {
+			return nil, err
+		}
+
+		for id, value := range entries {
+			merged[id] = value
+		}
+	}
+
+	return merged, nil
+}
+
+// Messages loads the message table name, e.g. "data\msgstringtable.txt".
+// Non-empty override messages replace the ones at the same position.
+func (l *Localizer) Messages(name string) ([]string, error) {
+	base, override, err := l.read(name)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := table.ParseMessages(base)
+	for i, message := range table.ParseMessages(override) {
+		if i >= len(messages) {
+			messages = append(messages, message)
+		} else if message != "" {
+			messages[i] = message
+		}
+	}
+
+	return messages, nil
+}
+
+// read returns the decoded contents of name from the game data and from the
+// overrides. A missing file yields an empty string, unless both are missing.
+func (l *Localizer) read(name string) (base, override string, err error) {
+	data, baseErr := l.source.ReadFile(name)
+	if baseErr != nil && !errors.Is(baseErr, fs.ErrNotExist) {
+		return "", "", baseErr
+	}
+
+	if base, err = l.Decode(data); err != nil {
+		return "", "", err
+	}
+
+	if l.overrides == nil {
+		return base, "", baseErr
+	}
+
+	data, err = l.overrides.ReadFile(name)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) || baseErr != nil {
+			return "", "", err
+		}
+
+		return base, "", nil
+	}
+
+	return base, string(data), nil
+}
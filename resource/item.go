@@ -0,0 +1,117 @@
+package resource
+
+import (
+	"fmt"
+	"image"
+	"io/fs"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/table"
+)
+
+const (
+	// ItemResNameTable maps item ids to the resource name of their sprites and images.
+	ItemResNameTable = "data\\idnum2itemresnametable.txt"
+	// CardIllustNameTable maps card ids to the name of their illustration.
+	CardIllustNameTable = "data\\num2cardillustnametable.txt"
+
+	// uiTexturePath is "data\texture\유저인터페이스\", EUC-KR encoded like GRF entry names.
+	uiTexturePath = "data\\texture\\\xc0\xaf\xc0\xfa\xc0\xce\xc5\xcd\xc6\xe4\xc0\xcc\xbd\xba\\"
+)
+
+// ItemImages loads item icons and card illustrations by item id, keeping the
+// decoded images in memory so windows can share them.
+type ItemImages struct {
+	loader Loader
+
+	resNames    map[int]string
+	illustNames map[int]string
+
+	mu    sync.Mutex
+	cache map[string]*image.NRGBA
+}
+
+// NewItemImages reads the item resource name table and, when present, the
+// card illustration table through loader.
+func NewItemImages(loader Loader) (*ItemImages, error) {
+	resNames, err := readTable(loader, ItemResNameTable)
+	if err != nil {
+		return nil, err
+	}
+
+	illustNames, err := readTable(loader, CardIllustNameTable)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	return &ItemImages{
+		loader:      loader,
+		resNames:    resNames,
+		illustNames: illustNames,
+		cache:       make(map[string]*image.NRGBA),
+	}, nil
+}
+
+// Icon returns the small inventory icon of an item.
+func (i *ItemImages) Icon(id int) (*image.NRGBA, error) {
+	return i.resource(id, "item")
+}
+
+// Collection returns the large image of an item shown in its description window.
+func (i *ItemImages) Collection(id int) (*image.NRGBA, error) {
+	return i.resource(id, "collection")
+}
+
+// CardIllustration returns the illustration of a card.
+func (i *ItemImages) CardIllustration(id int) (*image.NRGBA, error) {
+	name, ok := i.illustNames[id]
+	if !ok {
+		return nil, errors.Wrapf(fs.ErrNotExist, "no illustration for card %d", id)
+	}
+
+	return i.load(uiTexturePath + "cardbmp\\" + name + ".bmp")
+}
+
+func (i *ItemImages) resource(id int, dir string) (*image.NRGBA, error) {
+	name, ok := i.resNames[id]
+	if !ok {
+		return nil, errors.Wrapf(fs.ErrNotExist, "no resource name for item %d", id)
+	}
+
+	return i.load(fmt.Sprintf("%s%s\\%s.bmp", uiTexturePath, dir, name))
+}
+
+func (i *ItemImages) load(name string) (*image.NRGBA, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if img, ok := i.cache[name]; ok {
+		return img, nil
+	}
+
+	img, err := i.loader.LoadTexture(name)
+	if err != nil {
+		return nil, err
+	}
+
+	i.cache[name] = img
+
+	return img, nil
+}
+
+// readTable reads an id table. Names are kept as raw bytes, since they are
+// used to build EUC-KR entry names.
+func readTable(loader Loader, name string) (map[int]string, error) {
+	data, err := loader.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := table.Parse(string(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not parse '%s'", name)
+	}
+
+	return entries, nil
+}
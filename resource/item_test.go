@@ -0,0 +1,67 @@
+package resource_test
+
+import (
+	"fmt"
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/project-midgard/midgarts/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+const uiTexturePath = "data/texture/유저인터페이스/"
+
+func TestItemImages(t *testing.T) {
+	custom, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "custom.grf"))
+	assert.NoError(t, err)
+
+	bitmap, err := resource.NewGRFSource(custom).ReadFile("data\\0_Tex1.bmp")
+	assert.NoError(t, err)
+
+	loader := resource.NewLoader(resource.FSSource{FS: fstest.MapFS{
+		"data/idnum2itemresnametable.txt":       {Data: []byte("501#\xbb\xa1\xb0\xa3\xc6\xf7\xbc\xc7#\r\n4001#\xc6\xf7\xb8\xb5\xc4\xab\xb5\xe5#\r\n")},
+		"data/num2cardillustnametable.txt":      {Data: []byte("4001#cd_poring#\r\n")},
+		uiTexturePath + "item/빨간포션.bmp":         {Data: bitmap},
+		uiTexturePath + "collection/빨간포션.bmp":   {Data: bitmap},
+		uiTexturePath + "cardbmp/cd_poring.bmp": {Data: bitmap},
+	}})
+
+	images, err := resource.NewItemImages(loader)
+	assert.NoError(t, err)
+
+	icon, err := images.Icon(501)
+	assert.NoError(t, err)
+	assert.Equal(t, 256, icon.Bounds().Dx())
+
+	cached, err := images.Icon(501)
+	assert.NoError(t, err)
+	assert.Same(t, icon, cached)
+
+	_, err = images.Collection(501)
+	assert.NoError(t, err)
+
+	_, err = images.CardIllustration(4001)
+	assert.NoError(t, err)
+
+	_, err = images.Icon(4001)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = images.Icon(502)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = images.CardIllustration(501)
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestItemImagesMissingTables(t *testing.T) {
+	_, err := resource.NewItemImages(resource.NewLoader(resource.FSSource{FS: fstest.MapFS{}}))
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+
+	_, err = resource.NewItemImages(resource.NewLoader(resource.FSSource{FS: fstest.MapFS{
+		"data/idnum2itemresnametable.txt": {Data: []byte("501#Red_Potion#\r\n")},
+	}}))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,90 @@
+package resource
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/bmp"
+	"github.com/project-midgard/midgarts/fileformat/spr"
+	"github.com/project-midgard/midgarts/fileformat/tga"
+)
+
+// Loader loads decoded game resources by their GRF-style name, such as
+// "data\sprite\npc\1_f_maria.spr". Engine code should depend on it instead of
+// calling the fileformat packages, so tests can inject fakes.
+type Loader interface {
+	ReadFile(name string) ([]byte, error)
+	LoadSprite(name string) (*spr.SpriteFile, error)
+	LoadTexture(name string) (*image.NRGBA, error)
+}
+
+// Source provides the raw contents of resource files. Missing files return
+// an error wrapping fs.ErrNotExist.
+type Source interface {
+	ReadFile(name string) ([]byte, error)
+}
+
+// loader decodes resources read from a Source.
+type loader struct {
+	Source
+}
+
+// NewLoader returns a Loader decoding the files provided by src.
+func NewLoader(src Source) Loader {
+	return &loader{Source: src}
+}
+
+// LoadSprite loads and decodes a .spr file.
+func (l *loader) LoadSprite(name string) (*spr.SpriteFile, error) {
+	data, err := l.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := spr.Load(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load sprite '%s'", name)
+	}
+
+	return file, nil
+}
+
+// LoadTexture loads and decodes a .bmp or .tga texture.
+func (l *loader) LoadTexture(name string) (*image.NRGBA, error) {
+	data, err := l.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var img *image.NRGBA
+
+	switch ext := strings.ToLower(path.Ext(normalize(name))); ext {
+	case ".bmp":
+		var file *bmp.File
+		if file, err = bmp.Load(bytes.NewReader(data)); err == nil {
+			img = file.Image
+		}
+	case ".tga":
+		var file *tga.File
+		if file, err = tga.Load(bytes.NewReader(data)); err == nil {
+			img = file.Image
+		}
+	default:
+		return nil, fmt.Errorf("unsupported texture format '%s'", ext)
+	}
+
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not load texture '%s'", name)
+	}
+
+	return img, nil
+}
+
+// normalize converts a GRF-style name into a slash separated path.
+func normalize(name string) string {
+	return strings.ReplaceAll(name, "\\", "/")
+}
@@ -0,0 +1,93 @@
+package resource_test
+
+import (
+	"errors"
+	"fmt"
+	"image/color"
+	"io/fs"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"github.com/project-midgard/midgarts/fileformat/spr/sprtest"
+	"github.com/project-midgard/midgarts/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	dataPath = "./../data"
+)
+
+// texture builds a 1x1 top-down TGA with a single red pixel.
+func texture() []byte {
+	return []byte{
+		0, 0, 2, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 1, 0, 24, 0x20,
+		0x00, 0x00, 0xff,
+	}
+}
+
+func TestFSSource(t *testing.T) {
+	loader := resource.NewLoader(resource.FSSource{FS: fstest.MapFS{
+		"data/sprite/test.spr":  {Data: sprtest.Encode([]sprtest.Frame{{Width: 1, Height: 1, Data: []byte{0x01}}})},
+		"data/texture/test.tga": {Data: texture()},
+		"data/texture/test.png": {Data: []byte{}},
+	}})
+
+	file, err := loader.LoadSprite("data\\sprite\\test.spr")
+	assert.NoError(t, err)
+	assert.Len(t, file.Frames, 1)
+
+	img, err := loader.LoadTexture("data\\texture\\test.tga")
+	assert.NoError(t, err)
+	assert.Equal(t, color.NRGBA{R: 0xff, A: 0xff}, img.NRGBAAt(0, 0))
+
+	_, err = loader.LoadTexture("data\\texture\\test.png")
+	assert.Error(t, err)
+
+	_, err = loader.LoadSprite("data\\sprite\\missing.spr")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestGRFSource(t *testing.T) {
+	custom, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "custom.grf"))
+	assert.NoError(t, err)
+
+	withFiles, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"))
+	assert.NoError(t, err)
+
+	loader := resource.NewLoader(resource.NewGRFSource(custom, withFiles))
+
+	img, err := loader.LoadTexture("data\\0_Tex1.bmp")
+	assert.NoError(t, err)
+	assert.Equal(t, 256, img.Bounds().Dx())
+
+	data, err := loader.ReadFile("compressed")
+	assert.NoError(t, err)
+	assert.Equal(t, "test test test test test test test test test test test test test test test", string(data))
+
+	_, err = loader.ReadFile("missing")
+	assert.True(t, errors.Is(err, fs.ErrNotExist))
+}
+
+func TestGRFSourceConcurrentReads(t *testing.T) {
+	withFiles, err := grf.NewFile(fmt.Sprintf("%s/%s", dataPath, "with-files.grf"))
+	assert.NoError(t, err)
+	defer withFiles.Close()
+
+	source := resource.NewGRFSource(withFiles)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			data, err := source.ReadFile("compressed")
+			assert.NoError(t, err)
+			assert.Equal(t, "test test test test test test test test test test test test test test test", string(data))
+		}()
+	}
+	wg.Wait()
+}
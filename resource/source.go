@@ -0,0 +1,55 @@
+package resource
+
+import (
+	"io/fs"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/project-midgard/midgarts/fileformat/grf"
+	"golang.org/x/text/encoding/korean"
+)
+
+// FSSource reads resources from a file system, such as an extracted data
+// directory opened with os.DirFS. GRF-style names are converted to slash
+// separated paths, and EUC-KR names to the UTF-8 names extracted files have.
+type FSSource struct {
+	FS fs.FS
+}
+
+// ReadFile implements Source.
+func (s FSSource) ReadFile(name string) ([]byte, error) {
+	name = normalize(name)
+
+	if !utf8.ValidString(name) {
+		decoded, err := korean.EUCKR.NewDecoder().String(name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not decode name '%s'", name)
+		}
+
+		name = decoded
+	}
+
+	return fs.ReadFile(s.FS, name)
+}
+
+// GRFSource reads resources from a stack of GRF files. Files earlier in the
+// stack take precedence over the ones after them.
+type GRFSource struct {
+	files []*grf.File
+}
+
+// NewGRFSource returns a source reading from files, in order of precedence.
+func NewGRFSource(files ...*grf.File) *GRFSource {
+	return &GRFSource{files: files}
+}
+
+// ReadFile implements Source. It is safe for concurrent use.
+func (s *GRFSource) ReadFile(name string) ([]byte, error) {
+	for _, f := range s.files {
+		if _, exists := f.GetEntries()[name]; exists {
+			return f.ReadFile(name)
+		}
+	}
+
+	return nil, errors.Wrapf(fs.ErrNotExist, "could not find entry '%s'", name)
+}